@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/matheusrb95/fibergraph/internal/aws"
 	"github.com/matheusrb95/fibergraph/internal/data"
 	"github.com/matheusrb95/fibergraph/internal/database"
+	"github.com/matheusrb95/fibergraph/internal/jobs"
 
 	"github.com/joho/godotenv"
 )
@@ -38,11 +40,6 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("load aws config. %w", err)
 	}
 
-	db, err := database.Open()
-	if err != nil {
-		return fmt.Errorf("open db. %w", err)
-	}
-
 	services := aws.NewServices(cfg)
 	err = services.SNS.Ping()
 	if err != nil {
@@ -58,8 +55,19 @@ func run(ctx context.Context) error {
 	}
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slogLevel}))
-	models := data.NewModels(db)
-	srv := api.NewServer(logger, models, services)
+	source, err := topologySource()
+	if err != nil {
+		return err
+	}
+	queue := jobs.NewQueue(logger, envInt("JOB_QUEUE_SIZE", 100), envInt("JOB_WORKERS", 4))
+	defer queue.Shutdown()
+
+	limits := api.Limits{
+		CorrelationMaxBytes:      int64(envInt("CORRELATION_MAX_BYTES", 32<<20)),
+		BatchCorrelationMaxBytes: int64(envInt("BATCH_CORRELATION_MAX_BYTES", 64<<20)),
+	}
+
+	srv := api.NewServer(logger, source, services, queue, limits, os.Getenv("ADMIN_TOKEN"))
 
 	httpServer := &http.Server{
 		Addr:    ":4000",
@@ -88,3 +96,30 @@ func run(ctx context.Context) error {
 
 	return nil
 }
+
+func topologySource() (data.TopologySource, error) {
+	if path := os.Getenv("TOPOLOGY_FILE"); path != "" {
+		source, err := data.NewFileSource(path)
+		if err != nil {
+			return nil, fmt.Errorf("load topology file. %w", err)
+		}
+		return source, nil
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open db. %w", err)
+	}
+
+	ttl := time.Duration(envInt("TOPOLOGY_CACHE_TTL_SECONDS", 300)) * time.Second
+	return data.NewCachedSource(data.NewModels(db), ttl), nil
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/api"
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+)
+
+func runCorrelate(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("correlate", flag.ContinueOnError)
+	var in input
+	in.register(fs)
+	format := fs.String("format", "json", fmt.Sprintf("output format (json, %s)", strings.Join(correlation.FormatNames(), ", ")))
+	nodeID := fs.String("node", "", "render only the subtree under this node id")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var f *correlation.Format
+	if *format != "json" {
+		var err error
+		f, err = correlation.ParseFormat(*format)
+		if err != nil {
+			return err
+		}
+	}
+
+	bundle, err := in.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate. %w", err)
+	}
+
+	w, closeOutput, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	if f != nil {
+		err = render(w, c, f, *nodeID, correlation.DOTOptions{})
+	} else {
+		err = writeResult(w, c)
+	}
+	if err != nil {
+		closeOutput()
+		return err
+	}
+
+	return closeOutput()
+}
+
+func writeResult(w io.Writer, c *correlation.Correlation) error {
+	network := make([]api.NodeStatus, 0)
+	for _, node := range c.Result() {
+		network = append(network, api.NewNodeStatus(node))
+	}
+
+	incidents := make([]api.IncidentLocation, 0)
+	for _, incident := range c.Incidents() {
+		incidents = append(incidents, api.NewIncidentLocation(incident))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(map[string]any{"network": network, "incidents": incidents})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/evaluation"
+	"github.com/matheusrb95/fibergraph/internal/synthetic"
+)
+
+func runEvaluate(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	var in input
+	in.registerNetwork(fs)
+	gen := synthetic.DefaultConfig()
+	registerConfig(fs, &gen)
+	cfg := evaluation.Config{Trials: 100, Faults: []int{1, 2, 3}}
+	fs.IntVar(&cfg.Trials, "trials", cfg.Trials, "trials per fault count")
+	fs.Func("faults", "comma-separated numbers of simultaneous cuts to evaluate (default 1,2,3)", func(value string) error {
+		faults := make([]int, 0)
+		for _, item := range strings.Split(value, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+			faults = append(faults, n)
+		}
+		cfg.Faults = faults
+		return nil
+	})
+	fs.Float64Var(&cfg.Noise.Missing, "missing", 0, "probability of a device not reporting")
+	fs.Float64Var(&cfg.Noise.Inconsistent, "inconsistent", 0, "probability of a sensor reporting the opposite status")
+	fs.Float64Var(&cfg.Noise.DyingGasp, "dying-gasp", 0, "probability of a lit ONU reporting alarmed")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg.Seed = gen.Seed
+
+	// Without -snapshot or -topology the faults are injected into a
+	// generated network.
+	var network *synthetic.Network
+	if in.snapshot != "" || in.topology != "" {
+		bundle, err := in.load(ctx)
+		if err != nil {
+			return err
+		}
+		network = synthetic.FromTopology(bundle.Topology, gen.Seed)
+	} else {
+		var err error
+		network, err = synthetic.Generate(gen)
+		if err != nil {
+			return err
+		}
+	}
+
+	report, err := evaluation.Evaluate(ctx, network, cfg)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	}
+
+	return report.Write(stdout)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/database"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+
+	"github.com/joho/godotenv"
+)
+
+func runExport(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	tenantID := fs.String("tenant", "", "tenant id")
+	projectID := fs.String("project", "", "project id, comma-separated to merge several projects")
+	format := fs.String("format", "dot", fmt.Sprintf("output format (%s, snapshot)", strings.Join(correlation.FormatNames(), ", ")))
+	nodeID := fs.String("node", "", "render only the subtree under this node id")
+	statusPath := fs.String("status", "", "equipment status JSON file to correlate with")
+	anonymize := fs.Bool("anonymize", false, "anonymize the snapshot bundle")
+	output := fs.String("o", "", "output file (default stdout)")
+	var opts correlation.DOTOptions
+	fs.BoolVar(&opts.Cluster, "cluster", false, "group dot output by closure")
+	fs.BoolVar(&opts.Collapse, "collapse", false, "collapse fully active subtrees in dot output")
+	fs.BoolVar(&opts.Highlight, "highlight", false, "highlight paths to alarmed and inconsistent nodes in dot output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *tenantID == "" || *projectID == "" {
+		return errors.New("-tenant and -project are required")
+	}
+
+	var f *correlation.Format
+	if *format != "snapshot" {
+		var err error
+		f, err = correlation.ParseFormat(*format)
+		if err != nil {
+			return err
+		}
+	}
+
+	var status snapshot.EquipmentStatus
+	if *statusPath != "" {
+		var err error
+		status, err = readStatus(*statusPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	_ = godotenv.Load()
+
+	db, err := database.Open()
+	if err != nil {
+		return fmt.Errorf("open db. %w", err)
+	}
+	defer db.Close()
+
+	projectIDs := strings.Split(*projectID, ",")
+	topology, err := data.NewModels(db).LoadTopology(ctx, *tenantID, projectIDs...)
+	if err != nil {
+		return fmt.Errorf("load topology. %w", err)
+	}
+
+	bundle := snapshot.New(*tenantID, projectIDs, topology, status)
+
+	// A snapshot bundle is written as loaded, without correlating it.
+	if f == nil {
+		if *anonymize {
+			bundle, err = bundle.Anonymize()
+			if err != nil {
+				return err
+			}
+		}
+
+		w, closeOutput, err := openOutput(*output, stdout)
+		if err != nil {
+			return err
+		}
+
+		err = snapshot.Write(w, bundle)
+		if err != nil {
+			closeOutput()
+			return err
+		}
+
+		return closeOutput()
+	}
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate. %w", err)
+	}
+
+	w, closeOutput, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	err = render(w, c, f, *nodeID, opts)
+	if err != nil {
+		closeOutput()
+		return err
+	}
+
+	return closeOutput()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/synthetic"
+)
+
+func runGenerate(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	cfg := synthetic.DefaultConfig()
+	registerConfig(fs, &cfg)
+	format := fs.String("format", "snapshot", "output format (snapshot, json, yaml); json and yaml write a topology dataset")
+	tenantID := fs.String("tenant", "synthetic", "tenant id of the generated network")
+	projectID := fs.String("project", "1", "project id of the generated network")
+	cuts := fs.Int("cuts", 0, "number of independent random fiber cuts to inject into the snapshot status")
+	truth := fs.String("truth", "", "write the ground truth of the injected cuts to this file")
+	output := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	network, err := synthetic.Generate(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	cutIDs, err := network.RandomCuts(*cuts)
+	if err != nil {
+		return err
+	}
+
+	fault, err := network.Fault(cutIDs...)
+	if err != nil {
+		return err
+	}
+
+	w, closeOutput, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "snapshot":
+		err = snapshot.Write(w, network.Bundle(*tenantID, *projectID, fault))
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(network.Dataset(*tenantID, *projectID))
+	case "yaml":
+		err = yaml.NewEncoder(w).Encode(network.Dataset(*tenantID, *projectID))
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		closeOutput()
+		return err
+	}
+
+	if err := closeOutput(); err != nil {
+		return err
+	}
+
+	if *truth == "" {
+		return nil
+	}
+
+	tw, closeTruth, err := openOutput(*truth, stdout)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(tw)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(fault); err != nil {
+		closeTruth()
+		return err
+	}
+
+	return closeTruth()
+}
+
+func registerConfig(fs *flag.FlagSet, cfg *synthetic.Config) {
+	fs.Uint64Var(&cfg.Seed, "seed", cfg.Seed, "random seed, the same seed and flags generate the same network")
+	fs.IntVar(&cfg.COs, "cos", cfg.COs, "number of COs")
+	fs.IntVar(&cfg.DIOsPerCO, "dios", cfg.DIOsPerCO, "DIOs per CO")
+	fs.IntVar(&cfg.FeedersPerDIO, "feeders", cfg.FeedersPerDIO, "feeder fibers per DIO")
+	fs.IntVar(&cfg.Depth, "depth", cfg.Depth, "splitter levels below each feeder")
+	fs.Func("ratios", "comma-separated splitter output counts per level, the last repeats (default 8,16)", func(value string) error {
+		ratios := make([]int, 0)
+		for _, item := range strings.Split(value, ",") {
+			ratio, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+			ratios = append(ratios, ratio)
+		}
+		cfg.Ratios = ratios
+		return nil
+	})
+	fs.Float64Var(&cfg.Fill, "fill", cfg.Fill, "fraction of splitter outputs in use")
+	fs.IntVar(&cfg.ONUsPerDrop, "onus", cfg.ONUsPerDrop, "ONUs per drop fiber")
+	fs.Float64Var(&cfg.SensorDensity, "sensors", cfg.SensorDensity, "probability of a fiber carrying a sensor")
+	fs.Float64Var(&cfg.Protection, "protection", cfg.Protection, "probability of a feeder having a protection fiber from another DIO")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+)
+
+type impactReport struct {
+	NodeID     string   `json:"node_id"`
+	NodeType   string   `json:"node_type"`
+	Downstream int      `json:"downstream"`
+	Splitters  int      `json:"splitters"`
+	Closures   []string `json:"closures"`
+	ONUs       []string `json:"onus"`
+	Sensors    []string `json:"sensors"`
+}
+
+func runImpact(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("impact", flag.ContinueOnError)
+	var in input
+	in.register(fs)
+	nodeID := fs.String("node", "", "node id whose failure is assessed")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *nodeID == "" {
+		return errors.New("-node is required")
+	}
+
+	bundle, err := in.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate. %w", err)
+	}
+
+	node, ok := c.Node(*nodeID)
+	if !ok {
+		return fmt.Errorf("node %q not found", *nodeID)
+	}
+
+	report := impact(node)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	}
+
+	fmt.Fprintf(stdout, "%s %s\n", report.NodeType, report.NodeID)
+	fmt.Fprintf(stdout, "downstream nodes: %d\n", report.Downstream)
+	fmt.Fprintf(stdout, "splitters:        %d\n", report.Splitters)
+	fmt.Fprintf(stdout, "closures:         %d\n", len(report.Closures))
+	fmt.Fprintf(stdout, "onus:             %d\n", len(report.ONUs))
+	fmt.Fprintf(stdout, "sensors:          %d\n", len(report.Sensors))
+	for _, id := range report.ONUs {
+		fmt.Fprintf(stdout, "  ONU %s\n", id)
+	}
+	for _, id := range report.Sensors {
+		fmt.Fprintf(stdout, "  SENSOR %s\n", id)
+	}
+
+	return nil
+}
+
+func impact(node *correlation.Node) impactReport {
+	report := impactReport{
+		NodeID:   node.ID,
+		NodeType: node.Type.String(),
+		Closures: make([]string, 0),
+		ONUs:     make([]string, 0),
+		Sensors:  make([]string, 0),
+	}
+
+	closures := make(map[string]bool)
+	downstream := correlation.Downstream(node)
+	report.Downstream = len(downstream)
+
+	for _, n := range downstream {
+		switch n.Type {
+		case correlation.ONUNode:
+			report.ONUs = append(report.ONUs, n.ID)
+		case correlation.SensorNode:
+			report.Sensors = append(report.Sensors, n.ID)
+		case correlation.SplitterNode:
+			report.Splitters++
+		}
+
+		if n.Closure != nil && !closures[n.Closure.ID] {
+			closures[n.Closure.ID] = true
+			report.Closures = append(report.Closures, n.Closure.ID)
+		}
+	}
+
+	return report
+}
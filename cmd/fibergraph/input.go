@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+// input selects the network a command works on: either a snapshot bundle
+// or a JSON/YAML topology dataset plus the tenant and projects to read.
+type input struct {
+	snapshot  string
+	topology  string
+	tenantID  string
+	projectID string
+	status    string
+}
+
+func (in *input) register(fs *flag.FlagSet) {
+	in.registerNetwork(fs)
+	fs.StringVar(&in.status, "status", "", "equipment status JSON file, replaces the status of the snapshot")
+}
+
+// registerNetwork registers the flags selecting the network alone, for
+// commands that do not read its status.
+func (in *input) registerNetwork(fs *flag.FlagSet) {
+	fs.StringVar(&in.snapshot, "snapshot", "", "snapshot bundle file")
+	fs.StringVar(&in.topology, "topology", "", "JSON or YAML topology dataset file")
+	fs.StringVar(&in.tenantID, "tenant", "", "tenant id in the topology dataset")
+	fs.StringVar(&in.projectID, "project", "", "project id in the topology dataset, comma-separated to merge several projects")
+}
+
+func (in *input) load(ctx context.Context) (*snapshot.Bundle, error) {
+	var bundle *snapshot.Bundle
+
+	switch {
+	case in.snapshot != "" && in.topology != "":
+		return nil, errors.New("-snapshot and -topology are mutually exclusive")
+	case in.snapshot != "":
+		f, err := os.Open(in.snapshot)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		bundle, err = snapshot.Read(f)
+		if err != nil {
+			return nil, fmt.Errorf("read snapshot %s: %w", in.snapshot, err)
+		}
+	case in.topology != "":
+		if in.tenantID == "" || in.projectID == "" {
+			return nil, errors.New("-tenant and -project are required with -topology")
+		}
+
+		source, err := data.NewFileSource(in.topology)
+		if err != nil {
+			return nil, err
+		}
+
+		projectIDs := strings.Split(in.projectID, ",")
+		topology, err := source.LoadTopology(ctx, in.tenantID, projectIDs...)
+		if err != nil {
+			return nil, fmt.Errorf("load topology: %w", err)
+		}
+
+		bundle = &snapshot.Bundle{
+			Version:    snapshot.FormatVersion,
+			TenantID:   in.tenantID,
+			ProjectIDs: projectIDs,
+			Topology:   topology,
+		}
+	default:
+		return nil, errors.New("-snapshot or -topology is required")
+	}
+
+	if in.status != "" {
+		status, err := readStatus(in.status)
+		if err != nil {
+			return nil, err
+		}
+		bundle.EquipmentStatus = status
+	}
+
+	return bundle, nil
+}
+
+func readStatus(path string) (snapshot.EquipmentStatus, error) {
+	var status snapshot.EquipmentStatus
+
+	f, err := os.Open(path)
+	if err != nil {
+		return status, err
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&status)
+	if err != nil {
+		return status, fmt.Errorf("read status %s: %w", path, err)
+	}
+
+	return status, nil
+}
+
+func openOutput(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" {
+		return stdout, func() error { return nil }, nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f.Close, nil
+}
+
+func render(w io.Writer, c *correlation.Correlation, f *correlation.Format, nodeID string, opts correlation.DOTOptions) error {
+	roots := c.Roots()
+	if nodeID != "" {
+		node, ok := c.Node(nodeID)
+		if !ok {
+			return fmt.Errorf("node %q not found", nodeID)
+		}
+		roots = []*correlation.Node{node}
+	}
+
+	if f.Name == "dot" && opts != (correlation.DOTOptions{}) {
+		return c.DrawClusteredDOT(w, opts, roots...)
+	}
+
+	return f.Draw(w, roots...)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+type finding struct {
+	level   string
+	message string
+}
+
+type linter struct {
+	findings []finding
+}
+
+func (l *linter) errorf(format string, args ...any) {
+	l.findings = append(l.findings, finding{level: "error", message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) warnf(format string, args ...any) {
+	l.findings = append(l.findings, finding{level: "warning", message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) count(level string) int {
+	n := 0
+	for _, f := range l.findings {
+		if f.level == level {
+			n++
+		}
+	}
+
+	return n
+}
+
+func runLint(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	var in input
+	in.register(fs)
+	strict := fs.Bool("strict", false, "fail on warnings too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	bundle, err := in.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate. %w", err)
+	}
+
+	var l linter
+	lintTopology(&l, bundle)
+	lintStatus(&l, bundle)
+	lintReachability(&l, c)
+
+	for _, f := range l.findings {
+		fmt.Fprintf(stdout, "%s: %s\n", f.level, f.message)
+	}
+
+	errors, warnings := l.count("error"), l.count("warning")
+	fmt.Fprintf(stdout, "%d error(s), %d warning(s)\n", errors, warnings)
+
+	if errors > 0 || (*strict && warnings > 0) {
+		return fmt.Errorf("lint failed")
+	}
+
+	return nil
+}
+
+func lintTopology(l *linter, bundle *snapshot.Bundle) {
+	t := bundle.Topology
+
+	connections := make(map[string]string, len(t.Connections))
+	roots := 0
+	for _, connection := range t.Connections {
+		if previous, ok := connections[connection.ID]; ok && previous != connection.Type {
+			l.errorf("connection %s is listed as both %s and %s", connection.ID, previous, connection.Type)
+		}
+		connections[connection.ID] = connection.Type
+
+		if !slices.Contains(data.ConnectionTypes, connection.Type) {
+			l.warnf("connection %s has unknown type %q", connection.ID, connection.Type)
+		}
+		if connection.Type == "CO" {
+			roots++
+		}
+	}
+	if roots == 0 {
+		l.errorf("topology has no CO")
+	}
+
+	for _, connection := range t.Connections {
+		for _, parentID := range splitIDs(connection.ParentIDs) {
+			if _, ok := connections[parentID]; !ok {
+				l.errorf("connection %s has unknown parent %s", connection.ID, parentID)
+			}
+		}
+	}
+
+	for _, sensor := range t.Sensors {
+		if _, ok := connections[sensor.FiberID]; !ok {
+			l.errorf("sensor %s is attached to unknown fiber %q", sensor.DevEUI, sensor.FiberID)
+		}
+		if !validator.Matches(sensor.DevEUI, validator.DevEUIRX) {
+			l.warnf("sensor %s has a malformed DevEUI", sensor.DevEUI)
+		}
+	}
+
+	for _, onu := range t.ONUs {
+		if _, ok := connections[onu.FiberID]; !ok {
+			l.errorf("onu %s is attached to unknown fiber %q", onu.SerialNumber, onu.FiberID)
+		}
+		if !validator.Matches(onu.SerialNumber, validator.ONUSerialRX) {
+			l.warnf("onu %s has a malformed serial number", onu.SerialNumber)
+		}
+	}
+
+	for _, component := range t.Components {
+		for _, fiberID := range splitIDs(component.FiberIDs) {
+			if _, ok := connections[fiberID]; !ok {
+				l.warnf("%s %s references unknown fiber %s", component.Type, component.ID, fiberID)
+			}
+		}
+	}
+}
+
+func lintStatus(l *linter, bundle *snapshot.Bundle) {
+	sensors := make(map[string]bool)
+	for _, sensor := range bundle.Topology.Sensors {
+		sensors[sensor.DevEUI] = true
+	}
+
+	onus := make(map[string]bool)
+	for _, onu := range bundle.Topology.ONUs {
+		onus[onu.SerialNumber] = true
+	}
+
+	s := bundle.EquipmentStatus
+	lists := []struct {
+		name  string
+		ids   []string
+		known map[string]bool
+	}{
+		{"active_sensors", s.ActiveSensors, sensors},
+		{"alarmed_sensors", s.AlarmedSensors, sensors},
+		{"inactive_sensors", s.InactiveSensors, sensors},
+		{"active_onus", s.ActiveONUs, onus},
+		{"alarmed_onus", s.AlarmedONUs, onus},
+	}
+
+	listedIn := make(map[string]string)
+	for _, list := range lists {
+		for _, id := range list.ids {
+			if !list.known[id] {
+				l.warnf("%s lists unknown device %s", list.name, id)
+			}
+
+			if previous, ok := listedIn[id]; ok && previous != list.name {
+				l.errorf("device %s is listed in both %s and %s", id, previous, list.name)
+			}
+			listedIn[id] = list.name
+		}
+	}
+}
+
+func lintReachability(l *linter, c *correlation.Correlation) {
+	for _, node := range c.Nodes() {
+		// Closures and segments group fibers and never have parents.
+		switch node.Type {
+		case correlation.CONode, correlation.CEONode, correlation.CTONode, correlation.SegmentNode:
+			continue
+		}
+
+		if len(correlation.Upstream(node)) == 0 {
+			l.warnf("%s %s is not connected to any CO", node.Type, node.ID)
+		}
+	}
+}
+
+func splitIDs(ids *string) []string {
+	if ids == nil || *ids == "" {
+		return nil
+	}
+
+	return strings.Split(*ids, ",")
+}
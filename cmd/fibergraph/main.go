@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const usage = `usage: fibergraph <command> [flags]
+
+commands:
+  export     render or snapshot the topology of a tenant from the OSP database
+  correlate  run correlation on a snapshot or topology file
+  lint       check a snapshot or topology file for inconsistencies
+  impact     list the devices downstream of a node
+  stats      summarize the size and shape of a network
+  scenario   check scenario files against their expected node statuses
+  generate   build a synthetic FTTH network, optionally with fiber cuts
+  evaluate   score fault localization against injected cuts
+
+run "fibergraph <command> -h" for the flags of a command.`
+
+type command func(ctx context.Context, args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"export":    runExport,
+	"correlate": runCorrelate,
+	"lint":      runLint,
+	"impact":    runImpact,
+	"stats":     runStats,
+	"scenario":  runScenario,
+	"generate":  runGenerate,
+	"evaluate":  runEvaluate,
+}
+
+func main() {
+	ctx := context.Background()
+	err := run(ctx, os.Args[1:], os.Stdout)
+	// The flag package has already printed the usage for -h.
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintln(stdout, usage)
+		return nil
+	}
+
+	// Flags without a command keep the original database export working.
+	if strings.HasPrefix(args[0], "-") {
+		return runExport(ctx, args, stdout)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+
+	return cmd(ctx, args[1:], stdout)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/matheusrb95/fibergraph/internal/scenario"
+)
+
+func runScenario(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("scenario", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print the results as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("usage: fibergraph scenario [-json] file-or-directory...")
+	}
+
+	paths, err := scenarioFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	results := make([]*scenario.Result, 0)
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		scenarios, err := scenario.Load(path)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range scenarios {
+			results = append(results, s.Run())
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			fmt.Fprintln(stdout, result)
+		}
+		fmt.Fprintf(stdout, "%d scenario(s), %d failed\n", len(results), failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d scenario(s) failed", failed)
+	}
+
+	return nil
+}
+
+// scenarioFiles expands directories into the YAML files they contain.
+func scenarioFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			ext := filepath.Ext(path)
+			if path == arg || ext == ".yaml" || ext == ".yml" {
+				paths = append(paths, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
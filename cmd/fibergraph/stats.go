@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+)
+
+type statsReport struct {
+	Nodes          int            `json:"nodes"`
+	ByType         map[string]int `json:"by_type"`
+	ByStatus       map[string]int `json:"by_status"`
+	Roots          int            `json:"roots"`
+	MaxDepth       int            `json:"max_depth"`
+	MaxFanOut      int            `json:"max_splitter_fan_out"`
+	AvgFanOut      float64        `json:"avg_splitter_fan_out"`
+	MultiParent    int            `json:"multi_parent_nodes"`
+	Incidents      int            `json:"incidents"`
+	DevicesPerRoot float64        `json:"devices_per_root"`
+}
+
+func runStats(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	var in input
+	in.register(fs)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	bundle, err := in.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate. %w", err)
+	}
+
+	report := stats(c)
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	}
+
+	fmt.Fprintf(stdout, "nodes:              %d\n", report.Nodes)
+	fmt.Fprintf(stdout, "roots:              %d\n", report.Roots)
+	fmt.Fprintf(stdout, "max depth:          %d\n", report.MaxDepth)
+	fmt.Fprintf(stdout, "splitter fan-out:   max %d, avg %.2f\n", report.MaxFanOut, report.AvgFanOut)
+	fmt.Fprintf(stdout, "multi-parent nodes: %d\n", report.MultiParent)
+	fmt.Fprintf(stdout, "devices per root:   %.2f\n", report.DevicesPerRoot)
+	fmt.Fprintf(stdout, "incidents:          %d\n", report.Incidents)
+	fmt.Fprintln(stdout, "by type:")
+	for _, name := range slices.Sorted(maps.Keys(report.ByType)) {
+		fmt.Fprintf(stdout, "  %-18s %d\n", name, report.ByType[name])
+	}
+	fmt.Fprintln(stdout, "by status:")
+	for _, name := range slices.Sorted(maps.Keys(report.ByStatus)) {
+		fmt.Fprintf(stdout, "  %-18s %d\n", name, report.ByStatus[name])
+	}
+
+	return nil
+}
+
+func stats(c *correlation.Correlation) statsReport {
+	nodes := c.Nodes()
+	report := statsReport{
+		Nodes:     len(nodes),
+		ByType:    make(map[string]int),
+		ByStatus:  make(map[string]int),
+		Roots:     len(c.Roots()),
+		Incidents: len(c.Incidents()),
+	}
+
+	splitters, fanOut, devices := 0, 0, 0
+	for _, node := range nodes {
+		report.ByType[node.Type.String()]++
+		report.ByStatus[node.Status.String()]++
+
+		if len(node.Parents) > 1 {
+			report.MultiParent++
+		}
+
+		switch node.Type {
+		case correlation.SplitterNode:
+			splitters++
+			fanOut += len(node.Children)
+			report.MaxFanOut = max(report.MaxFanOut, len(node.Children))
+		case correlation.ONUNode, correlation.SensorNode:
+			devices++
+		}
+	}
+
+	if splitters > 0 {
+		report.AvgFanOut = float64(fanOut) / float64(splitters)
+	}
+	if report.Roots > 0 {
+		report.DevicesPerRoot = float64(devices) / float64(report.Roots)
+	}
+
+	depth := make(map[*correlation.Node]int)
+	queue := slices.Clone(c.Roots())
+	for _, root := range queue {
+		depth[root] = 0
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		report.MaxDepth = max(report.MaxDepth, depth[node])
+
+		for _, child := range node.Children {
+			if _, ok := depth[child]; ok {
+				continue
+			}
+			depth[child] = depth[node] + 1
+			queue = append(queue, child)
+		}
+	}
+
+	return report
+}
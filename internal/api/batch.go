@@ -0,0 +1,157 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/matheusrb95/fibergraph/internal/aws"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+const batchConcurrency = 4
+
+func HandleBatchCorrelation(logger *slog.Logger, source data.TopologySource, services *aws.Services, store *correlationStore, idempotency *idempotencyStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.PathValue("tenant_id")
+		if tenantID == "" {
+			notFoundResponse(w, r, logger)
+			return
+		}
+
+		lenient := r.URL.Query().Get("validation") == "lenient"
+
+		equipmentStatus, err := decodeEquipmentStatus(w, r, lenient)
+		if err != nil {
+			badRequestResponse(w, r, logger, err)
+			return
+		}
+
+		v := validator.New()
+		validateEquipmentStatus(v, equipmentStatus)
+		if !v.Valid() && !lenient {
+			failedValidationResponse(w, r, logger, v.Errors)
+			return
+		}
+
+		version := responseVersion(r)
+		qv := validator.New()
+		filter := readResultFilter(r, qv, version, "json")
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+		qv.Check(!dryRun || r.Header.Get(idempotencyKeyHeader) == "", idempotencyKeyHeader, "must not be sent with dry_run")
+		if !qv.Valid() {
+			failedValidationResponse(w, r, logger, qv.Errors)
+			return
+		}
+
+		idempotencyKey, ok := beginIdempotent(w, r, logger, idempotency, "batch/"+tenantID, equipmentStatus)
+		if !ok {
+			return
+		}
+
+		devices, err := source.Devices(r.Context(), tenantID)
+		if err != nil {
+			idempotency.Release(idempotencyKey)
+			dependencyErrorResponse(w, r, logger, err)
+			return
+		}
+
+		statuses, unassigned := splitByProject(devices, equipmentStatus)
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, batchConcurrency)
+		results := make(map[string]response.Envelope, len(statuses))
+
+		for projectID, status := range statuses {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				req := correlationRequest{
+					TenantID:        tenantID,
+					ProjectIDs:      []string{projectID},
+					EquipmentStatus: *status,
+					Lenient:         true,
+					Version:         version,
+					Filter:          filter,
+					DryRun:          dryRun,
+				}
+
+				env, commit, err := runCorrelation(r.Context(), logger, source, services, store, req)
+				if err != nil {
+					logger.Error(err.Error(), "tenant_id", tenantID, "project_id", projectID, "request_id", requestIDFromContext(r.Context()))
+					status, code, message := problemFor(err)
+					env = response.Envelope{"error": problem(r, status, code, message)}
+				} else {
+					commit()
+				}
+
+				mu.Lock()
+				results[projectID] = env
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		env := response.Envelope{"projects": results, "unassigned": unassigned}
+		if !v.Valid() {
+			env["warnings"] = v.Errors
+		}
+		idempotency.Complete(idempotencyKey, http.StatusOK, env, nil)
+
+		if filter.Compact {
+			err = response.CompactJSON(w, http.StatusOK, env)
+		} else {
+			err = response.JSON(w, http.StatusOK, env)
+		}
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func splitByProject(devices []*data.Device, equipmentStatus snapshot.EquipmentStatus) (map[string]*snapshot.EquipmentStatus, []string) {
+	projects := make(map[string][]string)
+	for _, device := range devices {
+		projects[device.ID] = append(projects[device.ID], device.ProjectID)
+	}
+
+	statuses := make(map[string]*snapshot.EquipmentStatus)
+	unassigned := make([]string, 0)
+
+	assign := func(ids []string, field func(*snapshot.EquipmentStatus) *[]string) {
+		for _, id := range ids {
+			projectIDs, ok := projects[id]
+			if !ok {
+				unassigned = append(unassigned, id)
+				continue
+			}
+
+			for _, projectID := range projectIDs {
+				status, ok := statuses[projectID]
+				if !ok {
+					status = &snapshot.EquipmentStatus{}
+					statuses[projectID] = status
+				}
+
+				list := field(status)
+				*list = append(*list, id)
+			}
+		}
+	}
+
+	assign(equipmentStatus.ActiveSensors, func(s *snapshot.EquipmentStatus) *[]string { return &s.ActiveSensors })
+	assign(equipmentStatus.AlarmedSensors, func(s *snapshot.EquipmentStatus) *[]string { return &s.AlarmedSensors })
+	assign(equipmentStatus.InactiveSensors, func(s *snapshot.EquipmentStatus) *[]string { return &s.InactiveSensors })
+	assign(equipmentStatus.ActiveONUs, func(s *snapshot.EquipmentStatus) *[]string { return &s.ActiveONUs })
+	assign(equipmentStatus.AlarmedONUs, func(s *snapshot.EquipmentStatus) *[]string { return &s.AlarmedONUs })
+
+	return statuses, unassigned
+}
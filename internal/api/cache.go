@@ -0,0 +1,76 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/request"
+	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+type OSPChangeEvent struct {
+	TenantID  string `json:"tenant_id"`
+	ProjectID string `json:"project_id"`
+}
+
+func HandleInvalidateTopology(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.PathValue("tenant_id")
+		if tenantID == "" {
+			notFoundResponse(w, r, logger)
+			return
+		}
+
+		projectID := r.PathValue("project_id")
+		invalidated := invalidateTopology(source, tenantID, projectID)
+		correlations := store.Invalidate(tenantID, projectID)
+
+		err := response.JSON(w, http.StatusOK, response.Envelope{"invalidated": invalidated, "invalidated_correlations": correlations})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func HandleOSPChangeEvent(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event OSPChangeEvent
+		err := request.DecodeJSON(w, r, &event)
+		if err != nil {
+			badRequestResponse(w, r, logger, err)
+			return
+		}
+
+		v := validator.New()
+		v.Check(event.TenantID != "", "tenant_id", "must be provided")
+		if !v.Valid() {
+			failedValidationResponse(w, r, logger, v.Errors)
+			return
+		}
+
+		invalidated := invalidateTopology(source, event.TenantID, event.ProjectID)
+		correlations := store.Invalidate(event.TenantID, event.ProjectID)
+		logger.Info("topology invalidated by osp change",
+			"tenant_id", event.TenantID,
+			"project_id", event.ProjectID,
+			"invalidated", invalidated,
+			"invalidated_correlations", correlations,
+		)
+
+		err = response.JSON(w, http.StatusAccepted, response.Envelope{"invalidated": invalidated, "invalidated_correlations": correlations})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func invalidateTopology(source data.TopologySource, tenantID, projectID string) int {
+	cached, ok := source.(data.Invalidator)
+	if !ok {
+		return 0
+	}
+
+	return cached.Invalidate(tenantID, projectID)
+}
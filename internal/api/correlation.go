@@ -1,24 +1,41 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/matheusrb95/fibergraph/internal/aws"
 	"github.com/matheusrb95/fibergraph/internal/correlation"
 	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/jobs"
 	"github.com/matheusrb95/fibergraph/internal/request"
 	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
 )
 
-type EquipmentStatus struct {
-	ActiveSensors   []string `json:"active_sensors"`
-	AlarmedSensors  []string `json:"alarmed_sensors"`
-	InactiveSensors []string `json:"inactive_sensors"`
-	ActiveONUs      []string `json:"active_onus"`
-	AlarmedONUs     []string `json:"alarmed_onus"`
+type Observation struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+}
+
+type correlationRequest struct {
+	TenantID        string
+	ProjectIDs      []string
+	EquipmentStatus snapshot.EquipmentStatus
+	Lenient         bool
+	Validator       *validator.Validator
+	Version         int
+	Filter          resultFilter
+	DryRun          bool
 }
 
 type ComponentStatus struct {
@@ -26,7 +43,31 @@ type ComponentStatus struct {
 	ComponentStatus string `json:"status"`
 }
 
-func HandleCorrelation(logger *slog.Logger, models *data.Models, services *aws.Services) http.Handler {
+type IncidentLocation struct {
+	ID                     string   `json:"id"`
+	Name                   string   `json:"name"`
+	Type                   string   `json:"type"`
+	Latitude               *float64 `json:"latitude"`
+	Longitude              *float64 `json:"longitude"`
+	PositionBasis          string   `json:"position_basis,omitempty"`
+	DistanceFromUpstream   *float64 `json:"distance_from_upstream"`
+	UpstreamClosureID      string   `json:"upstream_closure_id,omitempty"`
+	NearestClosureID       string   `json:"nearest_closure_id,omitempty"`
+	NearestClosureDistance float64  `json:"nearest_closure_distance,omitempty"`
+}
+
+func (cs ComponentStatus) field(name string) (any, bool) {
+	switch name {
+	case "name":
+		return cs.ComponentName, true
+	case "status":
+		return cs.ComponentStatus, true
+	default:
+		return nil, false
+	}
+}
+
+func HandleCorrelation(logger *slog.Logger, source data.TopologySource, services *aws.Services, store *correlationStore, queue *jobs.Queue, idempotency *idempotencyStore) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		tenantID := r.PathValue("tenant_id")
 		if tenantID == "" {
@@ -40,122 +81,416 @@ func HandleCorrelation(logger *slog.Logger, models *data.Models, services *aws.S
 			return
 		}
 
-		var equipmentStatus EquipmentStatus
-		err := request.DecodeJSON(w, r, &equipmentStatus)
+		lenient := r.URL.Query().Get("validation") == "lenient"
+
+		equipmentStatus, err := decodeEquipmentStatus(w, r, lenient)
 		if err != nil {
 			badRequestResponse(w, r, logger, err)
 			return
 		}
 
-		connections, err := models.Connection.GetAll(tenantID, projectID)
-		if err != nil {
-			serverErrorResponse(w, r, logger, err)
+		v := validator.New()
+		validateEquipmentStatus(v, equipmentStatus)
+		if !v.Valid() && !lenient {
+			failedValidationResponse(w, r, logger, v.Errors)
 			return
 		}
 
-		sensors, err := models.Sensor.GetAll(tenantID, projectID)
-		if err != nil {
-			serverErrorResponse(w, r, logger, err)
+		version := responseVersion(r)
+		qv := validator.New()
+		format, ok := resultFormat(r)
+		filter := readResultFilter(r, qv, version, format)
+		qv.Check(ok, "format", "must be one of json, csv, ndjson")
+		qv.Check(format == "json" || r.Header.Get(idempotencyKeyHeader) == "", idempotencyKeyHeader, "is only supported for JSON responses")
+		qv.Check(format == "json" || !lenient, "validation", "lenient validation is only supported for JSON responses")
+		qv.Check(format == "json" || (r.URL.Query().Get("projects") == "" && r.URL.Query().Get("scope") == ""), "projects", "merged projects are only supported for JSON responses")
+		callbackURL := r.URL.Query().Get("callback_url")
+		qv.Check(callbackURL == "" || jobs.CheckCallbackURL(callbackURL) == nil, "callback_url", "must be an absolute http or https URL of a public host")
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+		qv.Check(!dryRun || r.Header.Get(idempotencyKeyHeader) == "", idempotencyKeyHeader, "must not be sent with dry_run")
+		if !qv.Valid() {
+			failedValidationResponse(w, r, logger, qv.Errors)
 			return
 		}
 
-		onus, err := models.ONU.GetAll(tenantID, projectID)
+		projectIDs, err := mergedProjects(source, tenantID, projectID, r)
 		if err != nil {
-			serverErrorResponse(w, r, logger, err)
+			dependencyErrorResponse(w, r, logger, err)
 			return
 		}
 
-		components, err := models.Component.GetAll(tenantID, projectID)
-		if err != nil {
-			serverErrorResponse(w, r, logger, err)
+		idempotencyKey, ok := beginIdempotent(w, r, logger, idempotency, storeKey(tenantID, projectID), equipmentStatus)
+		if !ok {
 			return
 		}
 
-		logger.Info("network size",
-			"tenant_id", tenantID,
-			"project_id", projectID,
-			"connections_len", len(connections),
-			"sensors_len", len(sensors),
-			"onus_len", len(onus),
-			"components_len", len(components),
-		)
-
-		c := correlation.New(
-			connections,
-			sensors,
-			onus,
-			equipmentStatus.ActiveSensors,
-			equipmentStatus.AlarmedSensors,
-			equipmentStatus.InactiveSensors,
-			equipmentStatus.ActiveONUs,
-			equipmentStatus.AlarmedONUs,
-			components,
-		)
-		if err := c.Run(); err != nil {
-			serverErrorResponse(w, r, logger, err)
-			return
+		req := correlationRequest{
+			TenantID:        tenantID,
+			ProjectIDs:      projectIDs,
+			EquipmentStatus: equipmentStatus,
+			Lenient:         lenient,
+			Validator:       v,
+			Version:         version,
+			Filter:          filter,
+			DryRun:          dryRun,
 		}
 
-		projectIDint, err := strconv.Atoi(projectID)
-		if err != nil {
-			serverErrorResponse(w, r, logger, err)
+		if wantsAsync(r) {
+			job, err := queue.Submit(func(ctx context.Context) (any, func(), error) {
+				env, commit, err := runCorrelation(ctx, logger, source, services, store, req)
+				var vErr *validationError
+				if errors.As(err, &vErr) {
+					return response.Envelope{"errors": vErr.errors}, nil, err
+				}
+				return env, commit, err
+			}, callbackURL)
+			if err != nil {
+				idempotency.Release(idempotencyKey)
+				switch {
+				case errors.Is(err, jobs.ErrQueueFull):
+					serviceUnavailableResponse(w, r, logger, "queue_full", err.Error())
+				case errors.Is(err, jobs.ErrQueueClosed):
+					serviceUnavailableResponse(w, r, logger, "queue_closed", err.Error())
+				default:
+					serverErrorResponse(w, r, logger, err)
+				}
+				return
+			}
+
+			env := response.Envelope{"job": job}
+			headers := http.Header{"Location": []string{"/jobs/" + job.ID}}
+			idempotency.Complete(idempotencyKey, http.StatusAccepted, env, headers)
+			err = response.JSONWithHeaders(w, http.StatusAccepted, env, headers)
+			if err != nil {
+				serverErrorResponse(w, r, logger, err)
+			}
 			return
 		}
 
-		go func() {
-			for _, node := range c.Result() {
-				var topic string
-				var msg *data.SNSMessage
-				switch node.Type {
-				case correlation.ONUNode:
-					topic = "EH_ONU_EVENTS"
-					msg = data.NewONUMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, projectIDint, findOnuIDByNodeID(onus, node.ID))
-				case correlation.SensorNode:
-					topic = "EH_IOT_EVENTS"
-					msg = data.NewSensorMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, projectIDint)
-				case correlation.FiberNode:
-					continue
-				default:
-					topic = "EH_TOPOLOGIC_EVENTS"
-					msg = data.NewSensorMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, projectIDint)
-				}
+		if format != "json" {
+			c, commit, err := correlate(r.Context(), logger, source, services, store, req)
+			if err != nil {
+				dependencyErrorResponse(w, r, logger, err)
+				return
+			}
+			commit()
 
-				jsonBytes, err := json.Marshal(msg)
-				if err != nil {
-					logger.Warn("error marshaling sns message", "err", err.Error())
-					continue
-				}
+			_, page, next := filter.Apply(c.Result())
+			if next != "" {
+				w.Header().Set("X-Next-Cursor", next)
+			}
 
-				err = services.SNS.Publish(string(jsonBytes), topic)
-				if err != nil {
-					logger.Warn("error sending sns message", "err", err.Error())
-					continue
-				}
-				logger.Debug("sns message send.", "msg", string(jsonBytes))
+			err = writeNodes(w, format, page, filter.Fields)
+			if err != nil {
+				logError(r, logger, err)
 			}
-		}()
+			return
+		}
 
-		result := make([]ComponentStatus, 0)
-		for _, node := range c.Result() {
-			cs := ComponentStatus{ComponentName: node.Name, ComponentStatus: node.Status.String()}
-			result = append(result, cs)
+		env, commit, err := runCorrelation(r.Context(), logger, source, services, store, req)
+		if err != nil {
+			idempotency.Release(idempotencyKey)
+			dependencyErrorResponse(w, r, logger, err)
+			return
 		}
+		commit()
+		idempotency.Complete(idempotencyKey, http.StatusOK, env, nil)
 
-		err = response.JSON(w, http.StatusOK, response.Envelope{"network": result})
+		if filter.Compact {
+			err = response.CompactJSON(w, http.StatusOK, env)
+		} else {
+			err = response.JSON(w, http.StatusOK, env)
+		}
 		if err != nil {
 			serverErrorResponse(w, r, logger, err)
 		}
 	})
 }
 
-func findOnuIDByNodeID(onus []*data.ONU, nodeID string) string {
-	for _, onu := range onus {
-		if onu.SerialNumber != nodeID {
+func runCorrelation(ctx context.Context, logger *slog.Logger, source data.TopologySource, services *aws.Services, store *correlationStore, req correlationRequest) (response.Envelope, func(), error) {
+	if req.Validator == nil {
+		req.Validator = validator.New()
+	}
+
+	c, commit, err := correlate(ctx, logger, source, services, store, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := correlationEnvelope(c, req.Version, req.Filter)
+	if len(req.ProjectIDs) > 1 {
+		env["projects"] = req.ProjectIDs
+	}
+	if !req.Validator.Valid() {
+		env["warnings"] = req.Validator.Errors
+	}
+	if req.DryRun {
+		env["dry_run"] = true
+	}
+
+	return env, commit, nil
+}
+
+// correlate runs the correlation of req. Storing and publishing the result
+// is left to the returned commit, so callers can skip it when the run is
+// canceled after the fact.
+func correlate(ctx context.Context, logger *slog.Logger, source data.TopologySource, services *aws.Services, store *correlationStore, req correlationRequest) (*correlation.Correlation, func(), error) {
+	tenantID, projectIDs := req.TenantID, req.ProjectIDs
+
+	topology, err := loadTopology(ctx, logger, source, tenantID, projectIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validateDevices(req.Validator, req.EquipmentStatus, topology)
+	if !req.Validator.Valid() && !req.Lenient {
+		return nil, nil, &validationError{errors: req.Validator.Errors}
+	}
+
+	c, err := newCorrelation(topology, req.EquipmentStatus)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if req.DryRun {
+		return c, func() {}, nil
+	}
+
+	commit := func() {
+		store.Set(tenantID, projectIDs, c, topology, req.EquipmentStatus)
+		go publishResults(logger, services, c, tenantID, projectIDs[0])
+	}
+
+	return c, commit, nil
+}
+
+func decodeEquipmentStatus(w http.ResponseWriter, r *http.Request, lenient bool) (snapshot.EquipmentStatus, error) {
+	var equipmentStatus snapshot.EquipmentStatus
+
+	mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	if strings.TrimSpace(mediaType) == ndjsonContentType {
+		err := request.DecodeNDJSON(w, r, !lenient, func(o Observation) error { return observe(&equipmentStatus, o) })
+		return equipmentStatus, err
+	}
+
+	var err error
+	if lenient {
+		err = request.DecodeJSON(w, r, &equipmentStatus)
+	} else {
+		err = request.DecodeJSONStrict(w, r, &equipmentStatus)
+	}
+
+	return equipmentStatus, err
+}
+
+func observe(s *snapshot.EquipmentStatus, o Observation) error {
+	kind, status := strings.ToLower(o.Kind), strings.ToLower(o.Status)
+
+	switch {
+	case kind == "sensor" && status == "active":
+		s.ActiveSensors = append(s.ActiveSensors, o.ID)
+	case kind == "sensor" && status == "alarmed":
+		s.AlarmedSensors = append(s.AlarmedSensors, o.ID)
+	case kind == "sensor" && status == "inactive":
+		s.InactiveSensors = append(s.InactiveSensors, o.ID)
+	case kind == "onu" && status == "active":
+		s.ActiveONUs = append(s.ActiveONUs, o.ID)
+	case kind == "onu" && status == "alarmed":
+		s.AlarmedONUs = append(s.AlarmedONUs, o.ID)
+	case kind != "sensor" && kind != "onu":
+		return fmt.Errorf("observation kind must be sensor or onu, got %q", o.Kind)
+	default:
+		return fmt.Errorf("observation status %q is not valid for a %s", o.Status, kind)
+	}
+
+	return nil
+}
+
+// publishResults sends the status of every node to the project owning it,
+// falling back to projectID for nodes whose owner is unknown.
+func publishResults(logger *slog.Logger, services *aws.Services, c *correlation.Correlation, tenantID, projectID string) {
+	for _, node := range c.Result() {
+		owner := node.ProjectID
+		if owner == "" {
+			owner = projectID
+		}
+		ownerID, err := strconv.Atoi(owner)
+		if err != nil {
+			logger.Warn("invalid project id", "project_id", owner, "node_id", node.ID)
+			continue
+		}
+
+		var topic string
+		var msg *data.SNSMessage
+		switch node.Type {
+		case correlation.ONUNode:
+			topic = "EH_ONU_EVENTS"
+			msg = data.NewONUMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, ownerID, node.ComponentID)
+		case correlation.SensorNode:
+			topic = "EH_IOT_EVENTS"
+			msg = data.NewSensorMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, ownerID)
+		case correlation.FiberNode:
+			continue
+		default:
+			topic = "EH_TOPOLOGIC_EVENTS"
+			msg = data.NewSensorMessage(node.Type.String(), node.ID, node.Status.String(), tenantID, ownerID)
+		}
+
+		jsonBytes, err := json.Marshal(msg)
+		if err != nil {
+			logger.Warn("error marshaling sns message", "err", err.Error())
+			continue
+		}
+
+		err = services.SNS.Publish(string(jsonBytes), topic)
+		if err != nil {
+			logger.Warn("error sending sns message", "err", err.Error())
+			continue
+		}
+		logger.Debug("sns message send.", "msg", string(jsonBytes))
+	}
+}
+
+func mergedProjects(source data.TopologySource, tenantID, projectID string, r *http.Request) ([]string, error) {
+	qs := r.URL.Query()
+
+	var others []string
+	switch {
+	case qs.Get("scope") == "tenant":
+		projectIDs, err := source.Projects(r.Context(), tenantID)
+		if err != nil {
+			return nil, err
+		}
+		others = projectIDs
+	case qs.Get("projects") != "":
+		others = strings.Split(qs.Get("projects"), ",")
+
+		known, err := source.Projects(r.Context(), tenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		var unknown []string
+		for _, other := range others {
+			other = strings.TrimSpace(other)
+			if other != "" && !slices.Contains(known, other) {
+				unknown = append(unknown, other)
+			}
+		}
+		if len(unknown) > 0 {
+			v := validator.New()
+			v.AddError("projects", "unknown projects: "+strings.Join(unknown, ", "))
+			return nil, &validationError{errors: v.Errors}
+		}
+	}
+
+	result := []string{projectID}
+	for _, other := range others {
+		other = strings.TrimSpace(other)
+		if other == "" || slices.Contains(result, other) {
 			continue
 		}
+		result = append(result, other)
+	}
+
+	return result, nil
+}
+
+func wantsAsync(r *http.Request) bool {
+	if r.URL.Query().Get("async") == "true" {
+		return true
+	}
+
+	for _, preference := range strings.Split(r.Header.Get("Prefer"), ",") {
+		if strings.TrimSpace(preference) == "respond-async" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func loadTopology(ctx context.Context, logger *slog.Logger, source data.TopologySource, tenantID string, projectIDs []string) (*data.Topology, error) {
+	topology, err := source.LoadTopology(ctx, tenantID, projectIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, timing := range topology.Timings {
+		logger.Debug("topology query",
+			"tenant_id", tenantID,
+			"query", timing.Query,
+			"rows", timing.Rows,
+			"duration", timing.Duration,
+		)
+	}
+
+	logger.Info("network size",
+		"tenant_id", tenantID,
+		"project_ids", projectIDs,
+		"connections_len", len(topology.Connections),
+		"sensors_len", len(topology.Sensors),
+		"onus_len", len(topology.ONUs),
+		"components_len", len(topology.Components),
+		"spans_len", len(topology.Spans),
+		"locations_len", len(topology.Locations),
+		"routes_len", len(topology.Routes),
+	)
+
+	return topology, nil
+}
+
+func newCorrelation(topology *data.Topology, equipmentStatus snapshot.EquipmentStatus) (*correlation.Correlation, error) {
+	c := correlation.New(
+		topology.Connections,
+		topology.Sensors,
+		topology.ONUs,
+		equipmentStatus.ActiveSensors,
+		equipmentStatus.AlarmedSensors,
+		equipmentStatus.InactiveSensors,
+		equipmentStatus.ActiveONUs,
+		equipmentStatus.AlarmedONUs,
+		topology.Components,
+		topology.Spans,
+		topology.Locations,
+		topology.Routes,
+		topology.Owners,
+	)
+	if err := c.Run(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewIncidentLocation converts a located incident to its response
+// representation.
+func NewIncidentLocation(incident *correlation.Incident) IncidentLocation {
+	il := IncidentLocation{
+		ID:                   incident.Node.ID,
+		Name:                 incident.Node.Name,
+		Type:                 incident.Node.Type.String(),
+		PositionBasis:        incident.PositionBasis,
+		DistanceFromUpstream: incident.DistanceFromUpstream,
+	}
+
+	if incident.Position != nil {
+		il.Latitude = &incident.Position.Latitude
+		il.Longitude = &incident.Position.Longitude
+	}
+
+	if incident.UpstreamClosure != nil {
+		il.UpstreamClosureID = incident.UpstreamClosure.ID
+	}
 
-		return onu.ID
+	if incident.NearestClosure != nil {
+		il.NearestClosureID = incident.NearestClosure.ID
+		il.NearestClosureDistance = incident.NearestClosureDistance
 	}
 
-	return ""
+	return il
 }
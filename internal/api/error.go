@@ -1,48 +1,127 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
 	"github.com/matheusrb95/fibergraph/internal/response"
 )
 
+const problemContentType = "application/problem+json"
+
 func logError(r *http.Request, logger *slog.Logger, err error) {
 	logger.Error(
 		err.Error(),
 		"request_method", r.Method,
 		"request_url", r.URL.String(),
+		"request_id", requestIDFromContext(r.Context()),
 	)
 }
 
-func errorResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, status int, message interface{}) {
-	err := response.JSON(w, status, response.Envelope{"error": message})
+func errorResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, status int, code string, message interface{}) {
+	headers := http.Header{"Content-Type": []string{problemContentType}}
+	err := response.JSONWithHeaders(w, status, problem(r, status, code, message), headers)
 	if err != nil {
 		logError(r, logger, err)
 		w.WriteHeader(500)
 	}
 }
 
+// problem builds the RFC 9457 problem details object describing an error.
+func problem(r *http.Request, status int, code string, message interface{}) response.Envelope {
+	details := response.Envelope{
+		"type":       "urn:fibergraph:error:" + code,
+		"title":      http.StatusText(status),
+		"status":     status,
+		"code":       code,
+		"instance":   r.URL.Path,
+		"request_id": requestIDFromContext(r.Context()),
+	}
+
+	switch message := message.(type) {
+	case map[string]string:
+		details["detail"] = "The request contains invalid fields"
+		details["errors"] = message
+	default:
+		details["detail"] = message
+	}
+
+	return details
+}
+
 func serverErrorResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
 	logError(r, logger, err)
 
 	message := "The server encountered a problem and could not process your request"
-	errorResponse(w, r, logger, http.StatusInternalServerError, message)
+	errorResponse(w, r, logger, http.StatusInternalServerError, "internal_error", message)
 }
 
 func badRequestResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
-	errorResponse(w, r, logger, http.StatusBadRequest, err.Error())
+	errorResponse(w, r, logger, http.StatusBadRequest, "bad_request", err.Error())
 }
 
 func failedValidationResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, errors map[string]string) {
-	errorResponse(w, r, logger, http.StatusUnprocessableEntity, errors)
+	errorResponse(w, r, logger, http.StatusUnprocessableEntity, "validation_failed", errors)
 }
 
 func notFoundResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger) {
 	message := "the requested resource could not be found"
-	errorResponse(w, r, logger, http.StatusNotFound, message)
+	errorResponse(w, r, logger, http.StatusNotFound, "not_found", message)
+}
+
+func unauthorizedResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger) {
+	message := "a valid admin token is required to access this resource"
+	errorResponse(w, r, logger, http.StatusUnauthorized, "unauthorized", message)
+}
+
+func forbiddenResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, message string) {
+	errorResponse(w, r, logger, http.StatusForbidden, "forbidden", message)
 }
 
 func conflictResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, message string) {
-	errorResponse(w, r, logger, http.StatusConflict, message)
+	errorResponse(w, r, logger, http.StatusConflict, "conflict", message)
+}
+
+func serviceUnavailableResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, code, message string) {
+	errorResponse(w, r, logger, http.StatusServiceUnavailable, code, message)
+}
+
+func dependencyErrorResponse(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
+	var vErr *validationError
+	if errors.As(err, &vErr) {
+		failedValidationResponse(w, r, logger, vErr.errors)
+		return
+	}
+
+	status, code, message := problemFor(err)
+	if status == http.StatusInternalServerError {
+		serverErrorResponse(w, r, logger, err)
+		return
+	}
+
+	if status >= http.StatusInternalServerError {
+		logError(r, logger, err)
+	}
+	errorResponse(w, r, logger, status, code, message)
+}
+
+func problemFor(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, data.ErrTenantNotFound):
+		return http.StatusNotFound, "tenant_not_found", "the requested tenant could not be found"
+	case errors.Is(err, data.ErrProjectNotFound):
+		return http.StatusNotFound, "project_not_found", "the requested project could not be found"
+	case errors.Is(err, correlation.ErrNoNodes):
+		return http.StatusNotFound, "empty_topology", "the requested project has no central office to correlate from"
+	case errors.Is(err, data.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "database_timeout", "the topology database did not answer in time"
+	case errors.Is(err, data.ErrUnavailable):
+		return http.StatusServiceUnavailable, "database_unavailable", "the topology database is unavailable"
+	default:
+		return http.StatusInternalServerError, "internal_error", "The server encountered a problem and could not process your request"
+	}
 }
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+)
+
+func TestProblemFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "tenant not found", err: data.ErrTenantNotFound, wantStatus: http.StatusNotFound, wantCode: "tenant_not_found"},
+		{name: "wrapped project not found", err: fmt.Errorf("load topology: %w", data.ErrProjectNotFound), wantStatus: http.StatusNotFound, wantCode: "project_not_found"},
+		{name: "empty topology", err: correlation.ErrNoNodes, wantStatus: http.StatusNotFound, wantCode: "empty_topology"},
+		{name: "database timeout", err: data.ErrTimeout, wantStatus: http.StatusGatewayTimeout, wantCode: "database_timeout"},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout, wantCode: "database_timeout"},
+		{name: "database unavailable", err: data.ErrUnavailable, wantStatus: http.StatusServiceUnavailable, wantCode: "database_unavailable"},
+		{name: "record not found", err: data.ErrRecordNotFound, wantStatus: http.StatusInternalServerError, wantCode: "internal_error"},
+		{name: "other", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantCode: "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code, _ := problemFor(tt.err)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", status, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
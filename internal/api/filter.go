@@ -0,0 +1,217 @@
+package api
+
+import (
+	"cmp"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+const maxPageSize = 10_000
+
+var (
+	v1Fields  = []string{"name", "status"}
+	v2Fields  = []string{"id", "component_id", "name", "type", "status", "probability", "parent_ids", "onu_id"}
+	csvFields = []string{"id", "component_id", "name", "type", "status", "probability", "parent", "parent_ids", "onu_id"}
+)
+
+// resultFields lists the fields that can be selected in the given response
+// format and version.
+func resultFields(format string, version int) []string {
+	switch {
+	case format == "csv":
+		return csvFields
+	case format == "ndjson" || version == responseV2:
+		return v2Fields
+	default:
+		return v1Fields
+	}
+}
+
+type resultFilter struct {
+	Types           []correlation.NodeType
+	Statuses        []correlation.Status
+	ExcludeStatuses []correlation.Status
+	Fields          []string
+	Limit           int
+	After           *cursor
+	Compact         bool
+}
+
+// cursor is the position of the last node of a page in the (Type, ID) order
+// of paginated results.
+type cursor struct {
+	Type correlation.NodeType
+	ID   string
+}
+
+func newCursor(node *correlation.Node) cursor {
+	return cursor{Type: node.Type, ID: node.ID}
+}
+
+func parseCursor(value string) (*cursor, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+
+	name, id, ok := strings.Cut(string(decoded), ":")
+	if !ok || id == "" {
+		return nil, false
+	}
+
+	nt, ok := correlation.ParseNodeType(name)
+	if !ok {
+		return nil, false
+	}
+
+	return &cursor{Type: nt, ID: id}, true
+}
+
+func (c cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Type.String() + ":" + c.ID))
+}
+
+func (c cursor) compare(other cursor) int {
+	return cmp.Or(cmp.Compare(c.Type, other.Type), strings.Compare(c.ID, other.ID))
+}
+
+func readResultFilter(r *http.Request, v *validator.Validator, version int, format string) resultFilter {
+	qs := r.URL.Query()
+
+	var filter resultFilter
+
+	for _, name := range splitList(qs.Get("type")) {
+		nt, ok := correlation.ParseNodeType(name)
+		v.Check(ok, "type", "must contain only known node types")
+		filter.Types = append(filter.Types, nt)
+	}
+
+	for _, name := range splitList(qs.Get("status")) {
+		s, ok := correlation.ParseStatus(name)
+		v.Check(ok, "status", "must contain only known statuses")
+		filter.Statuses = append(filter.Statuses, s)
+	}
+
+	for _, name := range splitList(qs.Get("exclude_status")) {
+		s, ok := correlation.ParseStatus(name)
+		v.Check(ok, "exclude_status", "must contain only known statuses")
+		filter.ExcludeStatuses = append(filter.ExcludeStatuses, s)
+	}
+
+	permittedFields := resultFields(format, version)
+	for _, field := range splitList(qs.Get("fields")) {
+		v.Check(validator.In(field, permittedFields...), "fields", "must contain only "+strings.Join(permittedFields, ", "))
+		filter.Fields = append(filter.Fields, field)
+	}
+
+	if limit := qs.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		v.Check(err == nil && n > 0 && n <= maxPageSize, "limit", "must be an integer between 1 and "+strconv.Itoa(maxPageSize))
+		filter.Limit = n
+	}
+
+	if value := qs.Get("cursor"); value != "" {
+		after, ok := parseCursor(value)
+		v.Check(ok, "cursor", "must be a cursor returned by a previous page")
+		filter.After = after
+	}
+
+	filter.Compact = qs.Get("compact") == "true"
+
+	return filter
+}
+
+func (f resultFilter) Paginated() bool {
+	return f.Limit > 0 || f.After != nil
+}
+
+func (f resultFilter) Match(node *correlation.Node) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, node.Type) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, node.Status) {
+		return false
+	}
+
+	return !slices.Contains(f.ExcludeStatuses, node.Status)
+}
+
+// Apply returns the matching nodes, the requested page of them and the
+// cursor of the next page, if any.
+func (f resultFilter) Apply(nodes []*correlation.Node) (matched, page []*correlation.Node, next string) {
+	for _, node := range nodes {
+		if f.Match(node) {
+			matched = append(matched, node)
+		}
+	}
+
+	if !f.Paginated() {
+		return matched, matched, ""
+	}
+
+	page = slices.SortedFunc(slices.Values(matched), func(a, b *correlation.Node) int {
+		return newCursor(a).compare(newCursor(b))
+	})
+
+	if f.After != nil {
+		i, found := slices.BinarySearchFunc(page, *f.After, func(node *correlation.Node, after cursor) int {
+			return newCursor(node).compare(after)
+		})
+		if found {
+			i++
+		}
+		page = page[i:]
+	}
+
+	if f.Limit > 0 && len(page) > f.Limit {
+		page = page[:f.Limit]
+		next = newCursor(page[len(page)-1]).String()
+	}
+
+	return matched, page, next
+}
+
+// selectFields limits every item to the given fields, keeping the items
+// as they are when no fields are given.
+func selectFields[T interface{ field(string) (any, bool) }](items []T, fields []string) any {
+	if len(fields) == 0 {
+		return items
+	}
+
+	result := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		result = append(result, project(item, fields))
+	}
+
+	return result
+}
+
+func project[T interface{ field(string) (any, bool) }](item T, fields []string) map[string]any {
+	selected := make(map[string]any, len(fields))
+	for _, name := range fields {
+		if value, ok := item.field(name); ok {
+			selected[name] = value
+		}
+	}
+
+	return selected
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+func TestReadResultFilterFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		version int
+		format  string
+		valid   bool
+	}{
+		{name: "v1 json", query: "fields=name,status", version: responseV1, format: "json", valid: true},
+		{name: "v1 json rejects v2 fields", query: "fields=id", version: responseV1, format: "json"},
+		{name: "v2 json", query: "fields=id,probability,onu_id", version: responseV2, format: "json", valid: true},
+		{name: "csv", query: "fields=id,type", version: responseV1, format: "csv", valid: true},
+		{name: "csv parent", query: "fields=parent,probability", version: responseV1, format: "csv", valid: true},
+		{name: "ndjson", query: "fields=id,parent_ids", version: responseV1, format: "ndjson", valid: true},
+		{name: "ndjson rejects csv columns", query: "fields=parent", version: responseV1, format: "ndjson"},
+		{name: "unknown field", query: "fields=color", version: responseV2, format: "csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/?"+tt.query, nil)
+			v := validator.New()
+
+			readResultFilter(r, v, tt.version, tt.format)
+			if v.Valid() != tt.valid {
+				t.Errorf("got valid %v, want %v (errors %v)", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	node := correlation.NewNode("F-1", "fiber", correlation.FiberNode)
+	node.Probability = 0.5
+	items := []NodeStatus{NewNodeStatus(node)}
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   any
+	}{
+		{
+			name: "no fields",
+			want: items,
+		},
+		{
+			name:   "selected fields",
+			fields: []string{"id", "probability"},
+			want:   []map[string]any{{"id": "F-1", "probability": 0.5}},
+		},
+		{
+			name:   "empty onu id is omitted",
+			fields: []string{"id", "onu_id"},
+			want:   []map[string]any{{"id": "F-1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectFields(items, tt.fields)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  *cursor
+	}{
+		{name: "round trip", value: cursor{Type: correlation.CTONode, ID: "CTO-1"}.String(), want: &cursor{Type: correlation.CTONode, ID: "CTO-1"}},
+		{name: "id with colon", value: cursor{Type: correlation.FiberNode, ID: "F:1"}.String(), want: &cursor{Type: correlation.FiberNode, ID: "F:1"}},
+		{name: "lowercase type", value: encode("onu:ONU-1"), want: &cursor{Type: correlation.ONUNode, ID: "ONU-1"}},
+		{name: "not base64", value: "!!"},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte("CTO:C"))},
+		{name: "no separator", value: encode("CTO")},
+		{name: "empty id", value: encode("CTO:")},
+		{name: "unknown type", value: encode("POLE:P-1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCursor(tt.value)
+			if ok != (tt.want != nil) {
+				t.Fatalf("got ok %v, want %v", ok, tt.want != nil)
+			}
+			if ok && *got != *tt.want {
+				t.Errorf("got %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+const (
+	idempotencyTTL       = 24 * time.Hour
+	idempotencySize      = 10_000
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+type idempotencyState int
+
+const (
+	idempotencyNew idempotencyState = iota
+	idempotencyReplay
+	idempotencyInProgress
+	idempotencyMismatch
+)
+
+type idempotentResponse struct {
+	fingerprint string
+	done        bool
+	status      int
+	env         response.Envelope
+	headers     http.Header
+}
+
+// idempotencyStore remembers the responses sent for idempotency keys.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	responses *lruCache[*idempotentResponse]
+}
+
+func newIdempotencyStore(size int, ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{responses: newLRUCache[*idempotentResponse](size, ttl)}
+}
+
+// Begin claims key for a request with the given fingerprint.
+func (s *idempotencyStore) Begin(key, fingerprint string) (*idempotentResponse, idempotencyState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, ok := s.responses.Get(key)
+	switch {
+	case !ok:
+		s.responses.Set(key, &idempotentResponse{fingerprint: fingerprint})
+		return nil, idempotencyNew
+	case resp.fingerprint != fingerprint:
+		return nil, idempotencyMismatch
+	case !resp.done:
+		return nil, idempotencyInProgress
+	default:
+		return resp, idempotencyReplay
+	}
+}
+
+func (s *idempotencyStore) Complete(key string, status int, env response.Envelope, headers http.Header) {
+	if key == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, ok := s.responses.Peek(key)
+	if !ok {
+		return
+	}
+
+	resp.done = true
+	resp.status = status
+	resp.env = env
+	resp.headers = headers
+	s.responses.Set(key, resp)
+}
+
+func (s *idempotencyStore) Release(key string) {
+	if key == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses.Delete(key)
+}
+
+// beginIdempotent claims the Idempotency-Key sent with r within scope and
+// returns it, or an empty key when none was sent. It reports false when it
+// already answered the request, replaying or refusing it.
+func beginIdempotent(w http.ResponseWriter, r *http.Request, logger *slog.Logger, idempotency *idempotencyStore, scope string, equipmentStatus snapshot.EquipmentStatus) (string, bool) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		return "", true
+	}
+
+	fingerprint, err := requestFingerprint(r, equipmentStatus)
+	if err != nil {
+		serverErrorResponse(w, r, logger, err)
+		return "", false
+	}
+
+	key = scope + "/" + key
+	resp, state := idempotency.Begin(key, fingerprint)
+	switch state {
+	case idempotencyReplay:
+		headers := http.Header{"Idempotent-Replayed": []string{"true"}}
+		maps.Copy(headers, resp.headers)
+		err = response.JSONWithHeaders(w, resp.status, resp.env, headers)
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+		return "", false
+	case idempotencyInProgress:
+		conflictResponse(w, r, logger, "a request with this idempotency key is still being processed")
+		return "", false
+	case idempotencyMismatch:
+		errorResponse(w, r, logger, http.StatusUnprocessableEntity, "idempotency_key_reused", "the idempotency key was already used for a different request")
+		return "", false
+	}
+
+	return key, true
+}
+
+func requestFingerprint(r *http.Request, equipmentStatus snapshot.EquipmentStatus) (string, error) {
+	js, err := json.Marshal(equipmentStatus)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Header.Get("Accept")))
+	h.Write([]byte{0})
+	h.Write(js)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/response"
+)
+
+func TestIdempotencyStoreBegin(t *testing.T) {
+	tests := []struct {
+		name        string
+		steps       func(s *idempotencyStore)
+		fingerprint string
+		want        idempotencyState
+	}{
+		{
+			name:        "new key",
+			steps:       func(s *idempotencyStore) {},
+			fingerprint: "a",
+			want:        idempotencyNew,
+		},
+		{
+			name: "in progress",
+			steps: func(s *idempotencyStore) {
+				s.Begin("key", "a")
+			},
+			fingerprint: "a",
+			want:        idempotencyInProgress,
+		},
+		{
+			name: "replay",
+			steps: func(s *idempotencyStore) {
+				s.Begin("key", "a")
+				s.Complete("key", http.StatusOK, response.Envelope{"ok": true}, nil)
+			},
+			fingerprint: "a",
+			want:        idempotencyReplay,
+		},
+		{
+			name: "mismatch",
+			steps: func(s *idempotencyStore) {
+				s.Begin("key", "a")
+				s.Complete("key", http.StatusOK, response.Envelope{"ok": true}, nil)
+			},
+			fingerprint: "b",
+			want:        idempotencyMismatch,
+		},
+		{
+			name: "mismatch while in progress",
+			steps: func(s *idempotencyStore) {
+				s.Begin("key", "a")
+			},
+			fingerprint: "b",
+			want:        idempotencyMismatch,
+		},
+		{
+			name: "released",
+			steps: func(s *idempotencyStore) {
+				s.Begin("key", "a")
+				s.Release("key")
+			},
+			fingerprint: "b",
+			want:        idempotencyNew,
+		},
+		{
+			name: "complete without begin",
+			steps: func(s *idempotencyStore) {
+				s.Complete("key", http.StatusOK, response.Envelope{"ok": true}, nil)
+			},
+			fingerprint: "a",
+			want:        idempotencyNew,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newIdempotencyStore(10, time.Hour)
+			tt.steps(s)
+
+			resp, state := s.Begin("key", tt.fingerprint)
+			if state != tt.want {
+				t.Fatalf("got state %v, want %v", state, tt.want)
+			}
+			if (state == idempotencyReplay) != (resp != nil) {
+				t.Errorf("got response %v for state %v", resp, state)
+			}
+			if state == idempotencyReplay && resp.status != http.StatusOK {
+				t.Errorf("got status %d, want %d", resp.status, http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/matheusrb95/fibergraph/internal/jobs"
+	"github.com/matheusrb95/fibergraph/internal/response"
+)
+
+func HandleGetJob(logger *slog.Logger, queue *jobs.Queue) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Get(r.PathValue("job_id"))
+		if err != nil {
+			switch {
+			case errors.Is(err, jobs.ErrJobNotFound):
+				notFoundResponse(w, r, logger)
+			default:
+				serverErrorResponse(w, r, logger, err)
+			}
+			return
+		}
+
+		err = response.JSON(w, http.StatusOK, response.Envelope{"job": job})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func HandleCancelJob(logger *slog.Logger, queue *jobs.Queue) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Cancel(r.PathValue("job_id"))
+		if err != nil {
+			switch {
+			case errors.Is(err, jobs.ErrJobNotFound):
+				notFoundResponse(w, r, logger)
+			case errors.Is(err, jobs.ErrJobFinished):
+				conflictResponse(w, r, logger, err.Error())
+			default:
+				serverErrorResponse(w, r, logger, err)
+			}
+			return
+		}
+
+		err = response.JSON(w, http.StatusAccepted, response.Envelope{"job": job})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
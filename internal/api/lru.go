@@ -0,0 +1,126 @@
+package api
+
+import (
+	"container/list"
+	"time"
+)
+
+// lruCache holds at most size entries, each for ttl after it was last set.
+// Once full, the least recently used entry is evicted. It is not safe for
+// concurrent use; the stores built on it hold their own lock.
+type lruCache[V any] struct {
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newLRUCache[V any](size int, ttl time.Duration) *lruCache[V] {
+	return &lruCache[V]{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value of key and marks it as recently used.
+func (c *lruCache[V]) Get(key string) (V, bool) {
+	e, ok := c.live(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry[V]).value, true
+}
+
+// Peek returns the value of key without marking it as used.
+func (c *lruCache[V]) Peek(key string) (V, bool) {
+	e, ok := c.live(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	return e.Value.(*lruEntry[V]).value, true
+}
+
+// Set stores value under key, restarting its ttl.
+func (c *lruCache[V]) Set(key string, value V) {
+	now := c.now()
+
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expires = now.Add(c.ttl)
+		c.order.MoveToFront(e)
+		return
+	}
+
+	// Entries at the back are the least recently used; expired ones there
+	// go first so they do not take the place of live ones.
+	for back := c.order.Back(); back != nil && now.After(back.Value.(*lruEntry[V]).expires); back = c.order.Back() {
+		c.remove(back)
+	}
+	for c.order.Len() >= c.size && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value, expires: now.Add(c.ttl)})
+}
+
+func (c *lruCache[V]) Delete(key string) {
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+}
+
+// DeleteFunc removes every entry for which del returns true and reports how
+// many were removed.
+func (c *lruCache[V]) DeleteFunc(del func(key string, value V) bool) int {
+	removed := 0
+	for e := c.order.Front(); e != nil; {
+		next := e.Next()
+		entry := e.Value.(*lruEntry[V])
+		if del(entry.key, entry.value) {
+			c.remove(e)
+			removed++
+		}
+		e = next
+	}
+
+	return removed
+}
+
+func (c *lruCache[V]) Len() int {
+	return c.order.Len()
+}
+
+func (c *lruCache[V]) live(key string) (*list.Element, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if c.now().After(e.Value.(*lruEntry[V]).expires) {
+		c.remove(e)
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (c *lruCache[V]) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		steps   func(c *lruCache[int])
+		present []string
+		absent  []string
+	}{
+		{
+			name: "evicts the least recently used entry",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Get("a")
+				c.Set("c", 3)
+			},
+			present: []string{"a", "c"},
+			absent:  []string{"b"},
+		},
+		{
+			name: "peek does not mark an entry as used",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Peek("a")
+				c.Set("c", 3)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name: "expires entries after the ttl",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				now = now.Add(time.Minute)
+				c.Set("b", 2)
+				now = now.Add(time.Minute)
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+		},
+		{
+			name: "setting an entry again restarts its ttl",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				now = now.Add(time.Minute)
+				c.Set("a", 2)
+				now = now.Add(time.Minute)
+			},
+			present: []string{"a"},
+		},
+		{
+			name: "expired entries make room before live ones",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				c.Get("a")
+				now = now.Add(time.Minute)
+				c.Set("b", 3)
+				now = now.Add(time.Minute + time.Second)
+				c.Set("b", 4)
+				c.Set("c", 5)
+			},
+			present: []string{"b", "c"},
+			absent:  []string{"a"},
+		},
+		{
+			name: "delete func",
+			steps: func(c *lruCache[int]) {
+				c.Set("a", 1)
+				c.Set("b", 2)
+				if removed := c.DeleteFunc(func(_ string, v int) bool { return v == 1 }); removed != 1 {
+					t.Errorf("got %d removed, want 1", removed)
+				}
+			},
+			present: []string{"b"},
+			absent:  []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLRUCache[int](2, 90*time.Second)
+			c.now = func() time.Time { return now }
+
+			tt.steps(c)
+
+			for _, key := range tt.present {
+				if _, ok := c.Peek(key); !ok {
+					t.Errorf("%s is missing", key)
+				}
+			}
+			for _, key := range tt.absent {
+				if _, ok := c.Peek(key); ok {
+					t.Errorf("%s is still present", key)
+				}
+			}
+			if c.Len() > 2 {
+				t.Errorf("got %d entries, want at most 2", c.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/response"
+)
+
+type TopologyNode struct {
+	ID          string   `json:"id"`
+	ComponentID string   `json:"component_id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Status      string   `json:"status"`
+	ClosureID   string   `json:"closure_id,omitempty"`
+	ParentIDs   []string `json:"parent_ids"`
+	ChildrenIDs []string `json:"children_ids"`
+}
+
+func HandleTopologyNode(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node, ok := readTopologyNode(w, r, logger, source, store)
+		if !ok {
+			return
+		}
+
+		err := response.JSON(w, http.StatusOK, response.Envelope{"node": newTopologyNode(node)})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func HandleTopologyUpstream(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node, ok := readTopologyNode(w, r, logger, source, store)
+		if !ok {
+			return
+		}
+
+		err := response.JSON(w, http.StatusOK, response.Envelope{"upstream": newTopologyNodes(correlation.Upstream(node))})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func HandleTopologyDownstream(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node, ok := readTopologyNode(w, r, logger, source, store)
+		if !ok {
+			return
+		}
+
+		err := response.JSON(w, http.StatusOK, response.Envelope{"downstream": newTopologyNodes(correlation.Downstream(node))})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func HandleTopologySearch(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("q")
+		if query == "" {
+			failedValidationResponse(w, r, logger, map[string]string{"q": "must be provided"})
+			return
+		}
+
+		c, ok := readTopology(w, r, logger, source, store)
+		if !ok {
+			return
+		}
+
+		err := response.JSON(w, http.StatusOK, response.Envelope{"nodes": newTopologyNodes(c.Search(query))})
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+func readTopology(w http.ResponseWriter, r *http.Request, logger *slog.Logger, source data.TopologySource, store *correlationStore) (*correlation.Correlation, bool) {
+	tenantID := r.PathValue("tenant_id")
+	if tenantID == "" {
+		notFoundResponse(w, r, logger)
+		return nil, false
+	}
+
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		notFoundResponse(w, r, logger)
+		return nil, false
+	}
+
+	c, etag, err := latestCorrelation(r.Context(), logger, source, store, tenantID, projectID)
+	if err != nil {
+		dependencyErrorResponse(w, r, logger, err)
+		return nil, false
+	}
+
+	if notModified(w, r, etag) {
+		return nil, false
+	}
+
+	return c, true
+}
+
+func readTopologyNode(w http.ResponseWriter, r *http.Request, logger *slog.Logger, source data.TopologySource, store *correlationStore) (*correlation.Node, bool) {
+	c, ok := readTopology(w, r, logger, source, store)
+	if !ok {
+		return nil, false
+	}
+
+	node, ok := c.Node(r.PathValue("node_id"))
+	if !ok {
+		notFoundResponse(w, r, logger)
+		return nil, false
+	}
+
+	return node, true
+}
+
+func newTopologyNode(node *correlation.Node) TopologyNode {
+	tn := TopologyNode{
+		ID:          node.ID,
+		ComponentID: node.ComponentID,
+		Name:        node.Name,
+		Type:        node.Type.String(),
+		Status:      node.Status.String(),
+		ParentIDs:   node.ParentIDs(),
+		ChildrenIDs: node.ChildrenIDs(),
+	}
+
+	if node.Closure != nil {
+		tn.ClosureID = node.Closure.ID
+	}
+
+	return tn
+}
+
+func newTopologyNodes(nodes []*correlation.Node) []TopologyNode {
+	result := make([]TopologyNode, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, newTopologyNode(node))
+	}
+
+	return result
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/response"
+)
+
+const (
+	responseV1 = 1
+	responseV2 = 2
+)
+
+type NodeStatus struct {
+	ID          string   `json:"id"`
+	ComponentID string   `json:"component_id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Status      string   `json:"status"`
+	Probability float64  `json:"probability"`
+	ParentIDs   []string `json:"parent_ids"`
+	ONUID       string   `json:"onu_id,omitempty"`
+}
+
+type Summary struct {
+	Total    int                       `json:"total"`
+	ByType   map[string]map[string]int `json:"by_type"`
+	ByStatus map[string]int            `json:"by_status"`
+}
+
+func responseVersion(r *http.Request) int {
+	if r.URL.Query().Get("version") == "2" {
+		return responseV2
+	}
+
+	for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ = strings.Cut(strings.TrimSpace(mediaType), ";")
+		if mediaType == "application/vnd.fibergraph.v2+json" {
+			return responseV2
+		}
+	}
+
+	return responseV1
+}
+
+func correlationEnvelope(c *correlation.Correlation, version int, filter resultFilter) response.Envelope {
+	incidents := make([]IncidentLocation, 0)
+	for _, incident := range c.Incidents() {
+		incidents = append(incidents, NewIncidentLocation(incident))
+	}
+
+	matched, page, next := filter.Apply(c.Result())
+
+	var env response.Envelope
+	if version != responseV2 {
+		result := make([]ComponentStatus, 0, len(page))
+		for _, node := range page {
+			cs := ComponentStatus{ComponentName: node.Name, ComponentStatus: node.Status.String()}
+			result = append(result, cs)
+		}
+
+		env = response.Envelope{"network": selectFields(result, filter.Fields), "incidents": incidents}
+	} else {
+		result := make([]NodeStatus, 0, len(page))
+		for _, node := range page {
+			result = append(result, NewNodeStatus(node))
+		}
+
+		env = response.Envelope{
+			"version":   responseV2,
+			"network":   selectFields(result, filter.Fields),
+			"summary":   summarize(matched),
+			"incidents": incidents,
+		}
+	}
+
+	if filter.Paginated() {
+		env["page"] = response.Envelope{"total": len(matched), "count": len(page), "next_cursor": next}
+	}
+
+	return env
+}
+
+// NewNodeStatus converts a correlated node to its response representation.
+func NewNodeStatus(node *correlation.Node) NodeStatus {
+	ns := NodeStatus{
+		ID:          node.ID,
+		ComponentID: node.ComponentID,
+		Name:        node.Name,
+		Type:        node.Type.String(),
+		Status:      node.Status.String(),
+		Probability: node.Probability,
+		ParentIDs:   node.ParentIDs(),
+	}
+
+	if node.Type == correlation.ONUNode {
+		ns.ONUID = node.ComponentID
+	}
+
+	return ns
+}
+
+func (ns NodeStatus) field(name string) (any, bool) {
+	switch name {
+	case "id":
+		return ns.ID, true
+	case "component_id":
+		return ns.ComponentID, true
+	case "name":
+		return ns.Name, true
+	case "type":
+		return ns.Type, true
+	case "status":
+		return ns.Status, true
+	case "probability":
+		return ns.Probability, true
+	case "parent_ids":
+		return ns.ParentIDs, true
+	case "onu_id":
+		return ns.ONUID, ns.ONUID != ""
+	default:
+		return nil, false
+	}
+}
+
+func summarize(nodes []*correlation.Node) Summary {
+	summary := Summary{
+		Total:    len(nodes),
+		ByType:   make(map[string]map[string]int),
+		ByStatus: make(map[string]int),
+	}
+
+	for _, node := range nodes {
+		nodeType, status := node.Type.String(), node.Status.String()
+
+		if _, ok := summary.ByType[nodeType]; !ok {
+			summary.ByType[nodeType] = make(map[string]int)
+		}
+		summary.ByType[nodeType][status]++
+		summary.ByStatus[status]++
+	}
+
+	return summary
+}
@@ -1,17 +1,46 @@
 package api
 
 import (
+	"expvar"
 	"log/slog"
 	"net/http"
 
 	"github.com/matheusrb95/fibergraph/internal/aws"
 	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/jobs"
+	"github.com/matheusrb95/fibergraph/internal/request"
+	"github.com/matheusrb95/fibergraph/internal/web"
 )
 
-func NewServer(logger *slog.Logger, models *data.Models, services *aws.Services) http.Handler {
+type Limits struct {
+	CorrelationMaxBytes      int64
+	BatchCorrelationMaxBytes int64
+}
+
+// NewServer returns the API handler. The cache administration, OSP event and
+// debug endpoints require adminToken as a bearer token.
+func NewServer(logger *slog.Logger, source data.TopologySource, services *aws.Services, queue *jobs.Queue, limits Limits, adminToken string) http.Handler {
 	mux := http.NewServeMux()
+	store := newCorrelationStore(correlationStoreSize, correlationStoreTTL)
+	idempotency := newIdempotencyStore(idempotencySize, idempotencyTTL)
 
-	mux.Handle("POST /correlation/{tenant_id}/{project_id}", HandleCorrelation(logger, models, services))
+	mux.Handle("POST /correlation/{tenant_id}/{project_id}", request.MaxBytes(limits.CorrelationMaxBytes, HandleCorrelation(logger, source, services, store, queue, idempotency)))
+	mux.Handle("POST /correlation/{tenant_id}", request.MaxBytes(limits.BatchCorrelationMaxBytes, HandleBatchCorrelation(logger, source, services, store, idempotency)))
+	mux.Handle("GET /jobs/{job_id}", HandleGetJob(logger, queue))
+	mux.Handle("DELETE /jobs/{job_id}", HandleCancelJob(logger, queue))
+	mux.Handle("GET /topology/{tenant_id}/{project_id}", HandleTopology(logger, source, store))
+	mux.Handle("GET /topology/{tenant_id}/{project_id}/nodes/{node_id}", HandleTopologyNode(logger, source, store))
+	mux.Handle("GET /topology/{tenant_id}/{project_id}/nodes/{node_id}/upstream", HandleTopologyUpstream(logger, source, store))
+	mux.Handle("GET /topology/{tenant_id}/{project_id}/nodes/{node_id}/downstream", HandleTopologyDownstream(logger, source, store))
+	mux.Handle("GET /topology/{tenant_id}/{project_id}/search", HandleTopologySearch(logger, source, store))
+	mux.Handle("GET /snapshot/{tenant_id}/{project_id}", HandleExportSnapshot(logger, source, store))
+	mux.Handle("POST /snapshot", request.MaxBytes(limits.CorrelationMaxBytes, HandleRunSnapshot(logger)))
+	mux.Handle("DELETE /admin/topology/{tenant_id}", requireAdmin(logger, adminToken, HandleInvalidateTopology(logger, source, store)))
+	mux.Handle("DELETE /admin/topology/{tenant_id}/{project_id}", requireAdmin(logger, adminToken, HandleInvalidateTopology(logger, source, store)))
+	mux.Handle("POST /events/osp", requireAdmin(logger, adminToken, HandleOSPChangeEvent(logger, source, store)))
+	mux.Handle("GET /debug/vars", requireAdmin(logger, adminToken, expvar.Handler()))
+	mux.Handle("GET /ui/", http.StripPrefix("/ui", web.Handler()))
+	mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
 
-	return mux
+	return requestID(mux)
 }
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/request"
+	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+// HandleExportSnapshot exports the topology and equipment status of the
+// latest stored run of the project, so replaying the bundle reproduces it.
+// Without a stored run, the current topology is exported with no status.
+func HandleExportSnapshot(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.PathValue("tenant_id")
+		projectID := r.PathValue("project_id")
+
+		projectIDs, err := mergedProjects(source, tenantID, projectID, r)
+		if err != nil {
+			dependencyErrorResponse(w, r, logger, err)
+			return
+		}
+
+		topology, status, ok := store.Inputs(tenantID, projectIDs...)
+		if !ok {
+			topology, err = source.LoadTopology(r.Context(), tenantID, projectIDs...)
+			if err != nil {
+				dependencyErrorResponse(w, r, logger, err)
+				return
+			}
+		}
+
+		bundle := snapshot.New(tenantID, projectIDs, topology, status)
+		if r.URL.Query().Get("anonymize") == "true" {
+			bundle, err = bundle.Anonymize()
+			if err != nil {
+				serverErrorResponse(w, r, logger, err)
+				return
+			}
+		}
+
+		var buf bytes.Buffer
+		err = snapshot.Write(&buf, bundle)
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+			return
+		}
+
+		filename := fmt.Sprintf("snapshot-%s-%s.json", bundle.TenantID, bundle.ProjectIDs[0])
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	})
+}
+
+func HandleRunSnapshot(logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var bundle snapshot.Bundle
+		err := request.DecodeJSONStrict(w, r, &bundle)
+		if err != nil {
+			badRequestResponse(w, r, logger, err)
+			return
+		}
+
+		err = bundle.Check()
+		if err != nil {
+			if errors.Is(err, snapshot.ErrUnsupportedVersion) {
+				failedValidationResponse(w, r, logger, map[string]string{"version": err.Error()})
+				return
+			}
+			failedValidationResponse(w, r, logger, map[string]string{"topology": "must be provided"})
+			return
+		}
+
+		version := responseVersion(r)
+		qv := validator.New()
+		filter := readResultFilter(r, qv, version, "json")
+		if !qv.Valid() {
+			failedValidationResponse(w, r, logger, qv.Errors)
+			return
+		}
+
+		c, err := bundle.Correlation()
+		if err != nil {
+			dependencyErrorResponse(w, r, logger, err)
+			return
+		}
+
+		env := correlationEnvelope(c, version, filter)
+		env["snapshot"] = response.Envelope{
+			"tenant_id":   bundle.TenantID,
+			"project_ids": bundle.ProjectIDs,
+			"anonymized":  bundle.Anonymized,
+			"created_at":  bundle.CreatedAt,
+		}
+
+		if filter.Compact {
+			err = response.CompactJSON(w, http.StatusOK, env)
+		} else {
+			err = response.JSON(w, http.StatusOK, env)
+		}
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestHandleExportSnapshot(t *testing.T) {
+	source := data.NewMemorySource()
+	source.Add("acme", "1", &data.Topology{
+		Connections: []*data.Connection{{ID: "F-1", Name: "F-1", Type: "Fiber"}},
+	})
+
+	ran, err := source.LoadTopology(t.Context(), "acme", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := snapshot.EquipmentStatus{AlarmedONUs: []string{"ABCD00000001"}}
+
+	tests := []struct {
+		name        string
+		stored      bool
+		connections int
+		status      snapshot.EquipmentStatus
+	}{
+		{
+			name:        "no stored run",
+			connections: 2,
+		},
+		{
+			name:        "stored run keeps its topology",
+			stored:      true,
+			connections: 1,
+			status:      status,
+		},
+	}
+
+	// The topology changes after the run was stored.
+	source.Add("acme", "1", &data.Topology{
+		Connections: []*data.Connection{
+			{ID: "F-1", Name: "F-1", Type: "Fiber"},
+			{ID: "F-2", Name: "F-2", Type: "Fiber"},
+		},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCorrelationStore(correlationStoreSize, time.Hour)
+			if tt.stored {
+				store.Set("acme", []string{"1"}, nil, ran, status)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.SetPathValue("tenant_id", "acme")
+			r.SetPathValue("project_id", "1")
+			w := httptest.NewRecorder()
+			HandleExportSnapshot(testLogger(), source, store).ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			bundle, err := snapshot.Read(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(bundle.Topology.Connections); got != tt.connections {
+				t.Errorf("got %d connections, want %d", got, tt.connections)
+			}
+			if got, want := len(bundle.EquipmentStatus.AlarmedONUs), len(tt.status.AlarmedONUs); got != want {
+				t.Errorf("got %d alarmed onus, want %d", got, want)
+			}
+		})
+	}
+}
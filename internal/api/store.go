@@ -0,0 +1,116 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+const (
+	correlationStoreSize = 256
+	correlationStoreTTL  = 6 * time.Hour
+)
+
+type storedCorrelation struct {
+	tenantID    string
+	projectIDs  []string
+	correlation *correlation.Correlation
+	topology    *data.Topology
+	status      snapshot.EquipmentStatus
+	etag        string
+}
+
+// correlationStore keeps the latest correlation of each tenant and project
+// set, so a run merged over several projects does not replace the one of its
+// primary project.
+type correlationStore struct {
+	mu           sync.Mutex
+	correlations *lruCache[*storedCorrelation]
+}
+
+func newCorrelationStore(size int, ttl time.Duration) *correlationStore {
+	return &correlationStore{correlations: newLRUCache[*storedCorrelation](size, ttl)}
+}
+
+// Get returns the stored correlation if it was run on the topology of the
+// given version. A run on an older topology is dropped.
+func (s *correlationStore) Get(version, tenantID string, projectIDs ...string) (*correlation.Correlation, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := storeKey(tenantID, projectIDs...)
+	stored, ok := s.correlations.Get(key)
+	if !ok {
+		return nil, "", false
+	}
+	if stored.topology.Version != version {
+		s.correlations.Delete(key)
+		return nil, "", false
+	}
+
+	return stored.correlation, stored.etag, true
+}
+
+// Inputs returns the topology and equipment status the stored correlation
+// was run with, if any.
+func (s *correlationStore) Inputs(tenantID string, projectIDs ...string) (*data.Topology, snapshot.EquipmentStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.correlations.Peek(storeKey(tenantID, projectIDs...))
+	if !ok {
+		return nil, snapshot.EquipmentStatus{}, false
+	}
+
+	return stored.topology, stored.status, true
+}
+
+func (s *correlationStore) Set(tenantID string, projectIDs []string, c *correlation.Correlation, topology *data.Topology, status snapshot.EquipmentStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.correlations.Set(storeKey(tenantID, projectIDs...), &storedCorrelation{
+		tenantID:    tenantID,
+		projectIDs:  projectIDs,
+		correlation: c,
+		topology:    topology,
+		status:      status,
+		etag:        etag(topology.Version, status),
+	})
+}
+
+// Invalidate drops every stored correlation of the tenant that includes
+// projectID, or all of the tenant's correlations when projectID is empty.
+func (s *correlationStore) Invalidate(tenantID, projectID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.correlations.DeleteFunc(func(_ string, stored *storedCorrelation) bool {
+		return stored.tenantID == tenantID && (projectID == "" || slices.Contains(stored.projectIDs, projectID))
+	})
+}
+
+func storeKey(tenantID string, projectIDs ...string) string {
+	return tenantID + "/" + strings.Join(slices.Sorted(slices.Values(projectIDs)), ",")
+}
+
+// etag identifies a correlation by the version of its topology and the
+// equipment status it was run with.
+func etag(version string, status snapshot.EquipmentStatus) string {
+	js, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Sprintf(`"%s"`, version)
+	}
+
+	sum := sha256.Sum256(js)
+	return fmt.Sprintf(`"%s-%s"`, version, hex.EncodeToString(sum[:8]))
+}
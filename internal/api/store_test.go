@@ -0,0 +1,42 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+func TestCorrelationStoreGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		found   bool
+	}{
+		{name: "current topology", version: "v1", found: true},
+		{name: "topology changed", version: "v2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCorrelationStore(correlationStoreSize, time.Hour)
+			store.Set("acme", []string{"1"}, &correlation.Correlation{}, &data.Topology{Version: "v1"}, snapshot.EquipmentStatus{})
+
+			_, etag, ok := store.Get(tt.version, "acme", "1")
+			if ok != tt.found {
+				t.Fatalf("got found %v, want %v", ok, tt.found)
+			}
+			if ok && !strings.HasPrefix(etag, `"v1-`) {
+				t.Errorf("got etag %s, want one of version v1", etag)
+			}
+
+			// A run on an outdated topology is not served again.
+			if _, _, ok := store.Get("v1", "acme", "1"); ok != tt.found {
+				t.Errorf("got found %v after the first lookup, want %v", ok, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+)
+
+const (
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+var csvHeader = []string{"id", "type", "status", "probability", "parent"}
+
+func resultFormat(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		format = strings.ToLower(format)
+		return format, format == "json" || format == "csv" || format == "ndjson"
+	}
+
+	for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ = strings.Cut(strings.TrimSpace(mediaType), ";")
+		switch mediaType {
+		case csvContentType:
+			return "csv", true
+		case ndjsonContentType:
+			return "ndjson", true
+		}
+	}
+
+	return "json", true
+}
+
+// writeNodes streams nodes as CSV or NDJSON, limited to fields when any
+// are given.
+func writeNodes(w http.ResponseWriter, format string, nodes []*correlation.Node, fields []string) error {
+	switch format {
+	case "csv":
+		return writeCSV(w, nodes, fields)
+	default:
+		return writeNDJSON(w, nodes, fields)
+	}
+}
+
+func writeCSV(w http.ResponseWriter, nodes []*correlation.Node, fields []string) error {
+	w.Header().Set("Content-Type", csvContentType)
+	w.WriteHeader(http.StatusOK)
+
+	header := csvHeader
+	if len(fields) > 0 {
+		header = fields
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		record := make([]string, 0, len(header))
+		for _, column := range header {
+			record = append(record, csvValue(node, column))
+		}
+
+		err = cw.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvValue(node *correlation.Node, column string) string {
+	switch column {
+	case "id":
+		return node.ID
+	case "component_id":
+		return node.ComponentID
+	case "name":
+		return node.Name
+	case "type":
+		return node.Type.String()
+	case "status":
+		return node.Status.String()
+	case "probability":
+		return strconv.FormatFloat(node.Probability, 'f', -1, 64)
+	case "parent", "parent_ids":
+		return strings.Join(node.ParentIDs(), ";")
+	case "onu_id":
+		return NewNodeStatus(node).ONUID
+	default:
+		return ""
+	}
+}
+
+func writeNDJSON(w http.ResponseWriter, nodes []*correlation.Node, fields []string) error {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for _, node := range nodes {
+		ns := NewNodeStatus(node)
+		var line any = ns
+		if len(fields) > 0 {
+			line = project(ns, fields)
+		}
+
+		err := enc.Encode(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
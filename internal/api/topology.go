@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/response"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+func HandleTopology(logger *slog.Logger, source data.TopologySource, store *correlationStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.PathValue("tenant_id")
+		if tenantID == "" {
+			notFoundResponse(w, r, logger)
+			return
+		}
+
+		projectID, extension, _ := strings.Cut(r.PathValue("project_id"), ".")
+		if projectID == "" {
+			notFoundResponse(w, r, logger)
+			return
+		}
+
+		c, etag, err := latestCorrelation(r.Context(), logger, source, store, tenantID, projectID)
+		if err != nil {
+			dependencyErrorResponse(w, r, logger, err)
+			return
+		}
+
+		if notModified(w, r, etag) {
+			return
+		}
+
+		roots, nodes := c.Roots(), c.Nodes()
+		if id := r.URL.Query().Get("node"); id != "" {
+			node, ok := c.Node(id)
+			if !ok {
+				notFoundResponse(w, r, logger)
+				return
+			}
+			roots = []*correlation.Node{node}
+			nodes = append([]*correlation.Node{node}, correlation.Downstream(node)...)
+		}
+
+		format := topologyFormat(r, extension)
+		if format == "geojson" {
+			headers := http.Header{"Content-Type": []string{"application/geo+json"}}
+			err = response.JSONWithHeaders(w, http.StatusOK, response.Envelope{"type": "FeatureCollection", "features": c.Features()}, headers)
+			if err != nil {
+				serverErrorResponse(w, r, logger, err)
+			}
+			return
+		}
+
+		if format == "csv" || format == "ndjson" {
+			err = writeNodes(w, format, nodes, nil)
+			if err != nil {
+				logError(r, logger, err)
+			}
+			return
+		}
+
+		f, err := correlation.ParseFormat(format)
+		if err != nil {
+			badRequestResponse(w, r, logger, err)
+			return
+		}
+
+		draw := f.Draw
+		if opts := dotOptions(r); f.Name == "dot" && opts != (correlation.DOTOptions{}) {
+			draw = func(w io.Writer, roots ...*correlation.Node) error {
+				return c.DrawClusteredDOT(w, opts, roots...)
+			}
+		}
+
+		err = writeGraph(w, f.ContentType, draw, roots)
+		if err != nil {
+			serverErrorResponse(w, r, logger, err)
+		}
+	})
+}
+
+// latestCorrelation returns the stored correlation of the project while the
+// topology it ran on is still current, or the topology without equipment
+// status otherwise.
+func latestCorrelation(ctx context.Context, logger *slog.Logger, source data.TopologySource, store *correlationStore, tenantID, projectID string) (*correlation.Correlation, string, error) {
+	topology, err := loadTopology(ctx, logger, source, tenantID, []string{projectID})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if c, etag, ok := store.Get(topology.Version, tenantID, projectID); ok {
+		return c, etag, nil
+	}
+
+	c, err := newCorrelation(topology, snapshot.EquipmentStatus{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return c, etag(topology.Version, snapshot.EquipmentStatus{}), nil
+}
+
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+func topologyFormat(r *http.Request, extension string) string {
+	if extension != "" {
+		return extension
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ = strings.Cut(strings.TrimSpace(mediaType), ";")
+		switch mediaType {
+		case "application/geo+json":
+			return "geojson"
+		case csvContentType:
+			return "csv"
+		case ndjsonContentType:
+			return "ndjson"
+		}
+
+		if f, ok := correlation.FormatByContentType(mediaType); ok {
+			return f.Name
+		}
+	}
+
+	return "dot"
+}
+
+func dotOptions(r *http.Request) correlation.DOTOptions {
+	qs := r.URL.Query()
+	if qs.Get("view") == "clustered" {
+		return correlation.DOTOptions{Cluster: true, Collapse: true, Highlight: true}
+	}
+
+	return correlation.DOTOptions{
+		Cluster:   qs.Get("cluster") == "true",
+		Collapse:  qs.Get("collapse") == "true",
+		Highlight: qs.Get("highlight") == "true",
+	}
+}
+
+func writeGraph(w http.ResponseWriter, contentType string, draw func(io.Writer, ...*correlation.Node) error, roots []*correlation.Node) error {
+	var buf bytes.Buffer
+	err := draw(&buf, roots...)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+type validationError struct {
+	errors map[string]string
+}
+
+func (e *validationError) Error() string {
+	return "equipment status failed validation"
+}
+
+type deviceList struct {
+	field   string
+	ids     []string
+	rx      *regexp.Regexp
+	message string
+	sensor  bool
+}
+
+func deviceLists(s snapshot.EquipmentStatus) []deviceList {
+	devEUIMessage := "must be a 16 character hexadecimal DevEUI"
+	serialMessage := "must be a GPON serial number (4 characters followed by 8 hexadecimal digits)"
+
+	return []deviceList{
+		{field: "active_sensors", ids: s.ActiveSensors, rx: validator.DevEUIRX, message: devEUIMessage, sensor: true},
+		{field: "alarmed_sensors", ids: s.AlarmedSensors, rx: validator.DevEUIRX, message: devEUIMessage, sensor: true},
+		{field: "inactive_sensors", ids: s.InactiveSensors, rx: validator.DevEUIRX, message: devEUIMessage, sensor: true},
+		{field: "active_onus", ids: s.ActiveONUs, rx: validator.ONUSerialRX, message: serialMessage},
+		{field: "alarmed_onus", ids: s.AlarmedONUs, rx: validator.ONUSerialRX, message: serialMessage},
+	}
+}
+
+// validateEquipmentStatus checks the format of every listed device and that
+// each one is listed only once, within a list as well as across lists.
+func validateEquipmentStatus(v *validator.Validator, s snapshot.EquipmentStatus) {
+	type listing struct {
+		field string
+		key   string
+	}
+	listedAt := make(map[string]listing)
+
+	for _, list := range deviceLists(s) {
+		for i, id := range list.ids {
+			key := fmt.Sprintf("%s[%d]", list.field, i)
+
+			v.Check(validator.Matches(id, list.rx), key, list.message)
+
+			first, ok := listedAt[id]
+			switch {
+			case !ok:
+				listedAt[id] = listing{field: list.field, key: key}
+			case first.field == list.field:
+				v.AddError(key, fmt.Sprintf("%q is already listed at %s", id, first.key))
+			default:
+				v.AddError(key, fmt.Sprintf("%q is also listed in %s", id, first.field))
+			}
+		}
+	}
+}
+
+// validateDevices checks that every listed device belongs to the topology,
+// before any correlation is run on it.
+func validateDevices(v *validator.Validator, s snapshot.EquipmentStatus, topology *data.Topology) {
+	sensors := make(map[string]bool, len(topology.Sensors))
+	for _, sensor := range topology.Sensors {
+		sensors[sensor.DevEUI] = true
+	}
+
+	onus := make(map[string]bool, len(topology.ONUs))
+	for _, onu := range topology.ONUs {
+		onus[onu.SerialNumber] = true
+	}
+
+	for _, list := range deviceLists(s) {
+		known := onus
+		message := "unknown ONU serial number in this project"
+		if list.sensor {
+			known = sensors
+			message = "unknown sensor DevEUI in this project"
+		}
+
+		for i, id := range list.ids {
+			v.Check(known[id], fmt.Sprintf("%s[%d]", list.field, i), message)
+		}
+	}
+}
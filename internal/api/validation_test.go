@@ -0,0 +1,67 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/validator"
+)
+
+func TestValidateEquipmentStatus(t *testing.T) {
+	const (
+		sensor = "0011223344556677"
+		onu    = "ABCD12345678"
+	)
+
+	tests := []struct {
+		name   string
+		status snapshot.EquipmentStatus
+		want   map[string]string
+	}{
+		{
+			name: "valid",
+			status: snapshot.EquipmentStatus{
+				ActiveSensors: []string{sensor},
+				AlarmedONUs:   []string{onu},
+			},
+			want: map[string]string{},
+		},
+		{
+			name:   "bad format",
+			status: snapshot.EquipmentStatus{AlarmedSensors: []string{"ZZ"}, ActiveONUs: []string{"ONU"}},
+			want: map[string]string{
+				"alarmed_sensors[0]": "must be a 16 character hexadecimal DevEUI",
+				"active_onus[0]":     "must be a GPON serial number (4 characters followed by 8 hexadecimal digits)",
+			},
+		},
+		{
+			name:   "repeated within a list",
+			status: snapshot.EquipmentStatus{AlarmedSensors: []string{sensor, "8899AABBCCDDEEFF", sensor}},
+			want:   map[string]string{"alarmed_sensors[2]": `"0011223344556677" is already listed at alarmed_sensors[0]`},
+		},
+		{
+			name:   "listed in two lists",
+			status: snapshot.EquipmentStatus{ActiveONUs: []string{onu}, AlarmedONUs: []string{onu}},
+			want:   map[string]string{"alarmed_onus[0]": `"ABCD12345678" is also listed in active_onus`},
+		},
+		{
+			name:   "repeated and listed in another list",
+			status: snapshot.EquipmentStatus{ActiveSensors: []string{sensor, sensor}, InactiveSensors: []string{sensor}},
+			want: map[string]string{
+				"active_sensors[1]":   `"0011223344556677" is already listed at active_sensors[0]`,
+				"inactive_sensors[0]": `"0011223344556677" is also listed in active_sensors`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			validateEquipmentStatus(v, tt.status)
+			if !reflect.DeepEqual(v.Errors, tt.want) {
+				t.Errorf("got %v, want %v", v.Errors, tt.want)
+			}
+		})
+	}
+}
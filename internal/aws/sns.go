@@ -11,6 +11,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 )
 
+var (
+	ErrNoTopicPrefix = errors.New("no topic prefix")
+	ErrUnavailable   = errors.New("sns unavailable")
+)
+
 type SNSService struct {
 	Client *sns.Client
 }
@@ -20,13 +25,17 @@ func (s *SNSService) Ping() error {
 	defer cancel()
 
 	_, err := s.Client.ListTopics(ctx, &sns.ListTopicsInput{})
-	return err
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return nil
 }
 
 func (s *SNSService) Publish(msg, topic string) error {
 	topicPrefix := os.Getenv("SNS_TOPIC_PREFIX")
 	if topicPrefix == "" {
-		return errors.New("no topic prefix")
+		return ErrNoTopicPrefix
 	}
 	topicSufix := os.Getenv("SNS_TOPIC_SUFIX")
 	topicArn := fmt.Sprintf("%s:%s_%s", topicPrefix, topic, topicSufix)
@@ -38,7 +47,7 @@ func (s *SNSService) Publish(msg, topic string) error {
 
 	_, err := s.Client.Publish(context.TODO(), input)
 	if err != nil {
-		return fmt.Errorf("publish sns message. %w", err)
+		return fmt.Errorf("publish sns message. %w: %w", ErrUnavailable, err)
 	}
 
 	return nil
@@ -3,13 +3,14 @@ package correlation
 import (
 	"errors"
 	"fmt"
-	"os"
 	"slices"
 	"strings"
 
 	"github.com/matheusrb95/fibergraph/internal/data"
 )
 
+var ErrNoNodes = errors.New("no nodes")
+
 type Correlation struct {
 	Connections     []*data.Connection
 	Sensors         []*data.Sensor
@@ -20,9 +21,23 @@ type Correlation struct {
 	ActiveONUs      []string
 	AlarmedONUs     []string
 	Components      []*data.Component
+	Spans           []*data.Span
+	Locations       []*data.Location
+	Routes          []*data.RoutePoint
+	Owners          []*data.Owner
 
 	connectionNodes map[string]*Node
 	topologicNodes  []*Node
+	rootNodes       []*Node
+	closureNodes    map[string]*Node
+	componentFibers map[string][]string
+	positions       map[string]*Position
+	lengths         map[string]float64
+	spanEnds        map[string][]string
+	fiberSegments   map[string]string
+	routes          map[string][]*Position
+	reported        map[*Node]Status
+	incidents       []*Incident
 }
 
 func New(
@@ -35,6 +50,10 @@ func New(
 	activeONUs []string,
 	alarmedONUs []string,
 	components []*data.Component,
+	spans []*data.Span,
+	locations []*data.Location,
+	routes []*data.RoutePoint,
+	owners []*data.Owner,
 ) *Correlation {
 	return &Correlation{
 		Connections:     connections,
@@ -46,8 +65,21 @@ func New(
 		ActiveONUs:      activeONUs,
 		AlarmedONUs:     alarmedONUs,
 		Components:      components,
+		Spans:           spans,
+		Locations:       locations,
+		Routes:          routes,
+		Owners:          owners,
 		connectionNodes: make(map[string]*Node),
 		topologicNodes:  make([]*Node, 0),
+		closureNodes:    make(map[string]*Node),
+		componentFibers: make(map[string][]string),
+		positions:       make(map[string]*Position),
+		lengths:         make(map[string]float64),
+		spanEnds:        make(map[string][]string),
+		fiberSegments:   make(map[string]string),
+		routes:          make(map[string][]*Position),
+		reported:        make(map[*Node]Status),
+		incidents:       make([]*Incident, 0),
 	}
 }
 
@@ -55,33 +87,68 @@ func (c *Correlation) Result() []*Node {
 	return c.topologicNodes
 }
 
+func (c *Correlation) Roots() []*Node {
+	return c.rootNodes
+}
+
+func (c *Correlation) Node(id string) (*Node, bool) {
+	if node, ok := c.connectionNodes[id]; ok {
+		return node, true
+	}
+
+	for _, node := range c.topologicNodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
 func (c *Correlation) Run() error {
-	rootNodes := c.buildNetworkWithConnection()
-	if len(rootNodes) == 0 {
-		return errors.New("no nodes")
+	c.loadGeography()
+
+	c.rootNodes = c.buildNetworkWithConnection()
+	if len(c.rootNodes) == 0 {
+		return ErrNoNodes
 	}
 
-	for _, rootNode := range rootNodes {
+	for _, rootNode := range c.rootNodes {
 		for _, iCase := range InconsistentCases(rootNode) {
 			c.determineInconsistentSensor(iCase.AlarmedSensor, iCase.ActiveSensor)
 		}
 
 		propagateSensorStatus(rootNode)
 		propagateONUStatus(rootNode)
-
-		if os.Getenv("DRAW_CORRELATION") == "true" {
-			err := drawGraphs(rootNode)
-			if err != nil {
-				return err
-			}
-		}
 	}
 
 	c.determineComponentsStatus()
+	c.assignClosures()
+	c.assignProjects()
+	c.determineProbabilities()
+	c.locateIncidents()
 
 	return nil
 }
 
+// assignProjects sets the project owning the network component of each
+// node. A component shared by several projects goes to the first one listed.
+func (c *Correlation) assignProjects() {
+	owners := make(map[string]string, len(c.Owners))
+	for _, owner := range c.Owners {
+		if _, ok := owners[owner.NetworkComponentID]; !ok {
+			owners[owner.NetworkComponentID] = owner.ProjectID
+		}
+	}
+
+	for _, node := range c.connectionNodes {
+		node.ProjectID = owners[node.ComponentID]
+	}
+	for _, node := range c.topologicNodes {
+		node.ProjectID = owners[node.ComponentID]
+	}
+}
+
 func (c *Correlation) determineInconsistentSensor(alarmedNode, activeNode *Node) {
 	alarmedInList := slices.Contains(c.AlarmedSensors, alarmedNode.ID)
 	activeInList := slices.Contains(c.ActiveSensors, activeNode.ID)
@@ -100,6 +167,9 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 	result := make([]*Node, 0)
 
 	for _, connection := range c.Connections {
+		if _, ok := c.connectionNodes[connection.ID]; ok {
+			continue
+		}
 		c.updateConnectionMap(connection)
 
 		switch connection.Type {
@@ -110,14 +180,18 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 		}
 	}
 
+	seen := make(map[string]bool)
 	for _, sensor := range c.Sensors {
 		fiberNode, ok := c.connectionNodes[sensor.FiberID]
-		if !ok {
+		if !ok || seen[sensor.DevEUI] {
 			continue
 		}
+		seen[sensor.DevEUI] = true
 
 		name := fmt.Sprintf("%s - SPD", sensor.DevEUI)
 		node := NewNode(sensor.DevEUI, name, SensorNode)
+		node.ComponentID = sensor.ID
+		node.Position = c.positions[sensor.ID]
 
 		var status Status
 		switch sensor.Status {
@@ -138,6 +212,7 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 		}
 
 		node.Status = status
+		c.reported[node] = status
 		node.SetParents(fiberNode)
 
 		c.topologicNodes = append(c.topologicNodes, node)
@@ -145,12 +220,15 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 
 	for _, onu := range c.ONUs {
 		fiberNode, ok := c.connectionNodes[onu.FiberID]
-		if !ok {
+		if !ok || seen[onu.SerialNumber] {
 			continue
 		}
+		seen[onu.SerialNumber] = true
 
 		name := fmt.Sprintf("%s - ONU", onu.SerialNumber)
 		node := NewNode(onu.SerialNumber, name, ONUNode)
+		node.ComponentID = onu.ID
+		node.Position = c.positions[onu.ID]
 
 		var status Status
 		switch onu.Status {
@@ -169,6 +247,7 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 		}
 
 		node.Status = status
+		c.reported[node] = status
 		node.SetParents(fiberNode)
 
 		c.topologicNodes = append(c.topologicNodes, node)
@@ -190,6 +269,9 @@ func (c *Correlation) buildNetworkWithConnection() []*Node {
 			}
 
 			node := c.connectionNodes[connection.ID]
+			if slices.Contains(node.Parents, parentNode) {
+				continue
+			}
 			node.SetParents(parentNode)
 		}
 	}
@@ -216,11 +298,18 @@ func (c *Correlation) determineComponentsStatus() {
 			nodeType = SegmentNode
 		}
 		componentNode := NewNode(component.ID, name, nodeType)
+		componentNode.Position = c.positions[component.ID]
 
 		var hasActive, hasAlarmed, hasProbablyAlarmed, hasUndefined bool
 
-		fiberIDs := strings.SplitSeq(*component.FiberIDs, ",")
-		for fiberID := range fiberIDs {
+		fiberIDs := strings.Split(*component.FiberIDs, ",")
+		c.componentFibers[component.ID] = fiberIDs
+		switch nodeType {
+		case CEONode, CTONode, CONode:
+			c.closureNodes[component.ID] = componentNode
+		}
+
+		for _, fiberID := range fiberIDs {
 			node, ok := c.connectionNodes[fiberID]
 			if !ok {
 				continue
@@ -252,6 +341,90 @@ func (c *Correlation) determineComponentsStatus() {
 	}
 }
 
+func (c *Correlation) assignClosures() {
+	for _, component := range c.Components {
+		if _, ok := c.closureNodes[component.ID]; !ok {
+			continue
+		}
+
+		for _, fiberID := range c.componentFibers[component.ID] {
+			node, ok := c.connectionNodes[fiberID]
+			if !ok || node.Closure != nil {
+				continue
+			}
+			node.Closure = c.closureNodes[component.ID]
+		}
+	}
+
+	for _, node := range c.connectionNodes {
+		if node.Type != SplitterNode {
+			continue
+		}
+
+		for _, child := range node.Children {
+			if child.Closure != nil {
+				node.Closure = child.Closure
+				break
+			}
+		}
+	}
+}
+
+// determineProbabilities sets the probability of each node being faulty to
+// the share of alarmed devices among the devices downstream of it that
+// reported a status. Component nodes count the devices below their fibers.
+// Without any report below, only an alarmed node is certain.
+func (c *Correlation) determineProbabilities() {
+	for _, node := range c.connectionNodes {
+		node.Probability = c.alarmedShare(node, node)
+	}
+
+	for _, node := range c.topologicNodes {
+		below := []*Node{node}
+		if fiberIDs, ok := c.componentFibers[node.ID]; ok {
+			below = make([]*Node, 0, len(fiberIDs))
+			for _, fiberID := range fiberIDs {
+				if fiber, ok := c.connectionNodes[fiberID]; ok {
+					below = append(below, fiber)
+				}
+			}
+		}
+		node.Probability = c.alarmedShare(node, below...)
+	}
+}
+
+func (c *Correlation) alarmedShare(node *Node, below ...*Node) float64 {
+	var alarmed, reported int
+	seen := make(map[*Node]bool)
+	stack := slices.Clone(below)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+
+		switch c.reported[current] {
+		case Alarmed:
+			alarmed++
+			reported++
+		case Active:
+			reported++
+		}
+		stack = append(stack, current.Children...)
+	}
+
+	switch {
+	case reported > 0:
+		return float64(alarmed) / float64(reported)
+	case node.Status == Alarmed:
+		return 1
+	default:
+		return 0
+	}
+}
+
 func (c *Correlation) updateConnectionMap(connection *data.Connection) {
 	if _, ok := c.connectionNodes[connection.ID]; ok {
 		return
@@ -269,7 +442,11 @@ func (c *Correlation) updateConnectionMap(connection *data.Connection) {
 	case "Splitter":
 		nodeType = SplitterNode
 	}
-	c.connectionNodes[connection.ID] = NewNode(connection.ID, name, nodeType)
+	node := NewNode(connection.ID, name, nodeType)
+	node.Position = c.positions[connection.ID]
+	node.Length = c.lengths[connection.ID]
+
+	c.connectionNodes[connection.ID] = node
 }
 
 func propagateSensorStatus(node *Node) {
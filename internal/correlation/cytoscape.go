@@ -0,0 +1,52 @@
+package correlation
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type cytoscapeElement struct {
+	Data map[string]string `json:"data"`
+}
+
+type cytoscapeGraph struct {
+	Elements struct {
+		Nodes []cytoscapeElement `json:"nodes"`
+		Edges []cytoscapeElement `json:"edges"`
+	} `json:"elements"`
+}
+
+func DrawCytoscape(w io.Writer, roots ...*Node) error {
+	nodes, edges := subgraph(roots...)
+
+	var g cytoscapeGraph
+	g.Elements.Nodes = make([]cytoscapeElement, 0, len(nodes))
+	g.Elements.Edges = make([]cytoscapeElement, 0, len(edges))
+
+	for _, n := range nodes {
+		data := map[string]string{
+			"id":           n.ID,
+			"component_id": n.ComponentID,
+			"name":         n.Name,
+			"type":         n.Type.String(),
+			"status":       n.Status.String(),
+			"color":        n.Status.Color(),
+		}
+		if n.Closure != nil {
+			data["closure_id"] = n.Closure.ID
+			data["closure_type"] = n.Closure.Type.String()
+			data["closure_status"] = n.Closure.Status.String()
+		}
+
+		g.Elements.Nodes = append(g.Elements.Nodes, cytoscapeElement{Data: data})
+	}
+	for _, e := range edges {
+		g.Elements.Edges = append(g.Elements.Edges, cytoscapeElement{Data: map[string]string{
+			"id":     e[0].ID + "->" + e[1].ID,
+			"source": e[0].ID,
+			"target": e[1].ID,
+		}})
+	}
+
+	return json.NewEncoder(w).Encode(g)
+}
@@ -1,72 +1,268 @@
 package correlation
 
 import (
+	"bufio"
 	"fmt"
-	"os"
-
-	"github.com/dominikbraun/graph"
-	"github.com/dominikbraun/graph/draw"
+	"io"
+	"strings"
 )
 
-var counter int
+var statusColor = map[Status]string{
+	Active:          "green",
+	Alarmed:         "red",
+	ProbablyAlarmed: "orange",
+	Undefined:       "black",
+	Inconsistent:    "pink",
+}
+
+var legendStatuses = []Status{Active, Alarmed, ProbablyAlarmed, Inconsistent, Undefined}
+
+func (s Status) Color() string {
+	return statusColor[s]
+}
+
+func DrawDOT(w io.Writer, roots ...*Node) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph fibergraph {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+
+	nodes, edges := subgraph(roots...)
+	for _, n := range nodes {
+		fmt.Fprintf(bw, "\t%s [label=%s, color=%s];\n", dotQuote(n.ID), dotQuote(n.Name), n.Status.Color())
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "\t%s -> %s;\n", dotQuote(e[0].ID), dotQuote(e[1].ID))
+	}
+
+	writeDOTLegend(bw)
 
-func drawGraphs(node *Node) error {
-	g := graph.New(graph.StringHash, graph.Directed())
+	fmt.Fprintln(bw, "}")
 
-	walk(g, node)
+	return bw.Flush()
+}
 
-	counter++
-	file, err := os.Create(fmt.Sprintf("%s-%d.gv", "my-graph", counter))
-	if err != nil {
-		return err
+func writeDOTLegend(w io.Writer) {
+	fmt.Fprintln(w, "\tsubgraph cluster_legend {")
+	fmt.Fprintln(w, "\t\tlabel=\"Legend\";")
+	for _, status := range legendStatuses {
+		fmt.Fprintf(w, "\t\t%s [label=%s, color=%s];\n", dotQuote("legend_"+status.String()), dotQuote(status.String()), status.Color())
 	}
-	defer file.Close()
+	fmt.Fprintln(w, "\t}")
+}
+
+func subgraph(roots ...*Node) ([]*Node, [][2]*Node) {
+	nodes := make([]*Node, 0)
+	edges := make([][2]*Node, 0)
+	seen := make(map[*Node]bool)
 
-	err = draw.DOT(g, file)
-	if err != nil {
-		return err
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		nodes = append(nodes, n)
+
+		for _, child := range n.Children {
+			edges = append(edges, [2]*Node{n, child})
+			walk(child)
+		}
 	}
 
-	return nil
+	for _, root := range roots {
+		walk(root)
+	}
+
+	return nodes, edges
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
 }
 
-func walk(g graph.Graph[string, string], n *Node) {
-	var attr func(*graph.VertexProperties)
-	attr = graph.VertexAttribute("color", "black")
-
-	switch n.Status {
-	case Alarmed:
-		attr = graph.VertexAttribute("color", "red")
-	case ProbablyAlarmed:
-		attr = graph.VertexAttribute("color", "orange")
-	case Inconsistent:
-		attr = graph.VertexAttribute("color", "pink")
-	case Active:
-		attr = graph.VertexAttribute("color", "green")
-	default:
-		attr = graph.VertexAttribute("color", "black")
-	}
-	_ = g.AddVertex(n.ID, graph.VertexAttribute("label", n.Name), attr)
-
-	for _, child := range n.Children {
-		var attr func(*graph.VertexProperties)
-
-		switch child.Status {
-		case Alarmed:
-			attr = graph.VertexAttribute("color", "red")
-		case ProbablyAlarmed:
-			attr = graph.VertexAttribute("color", "orange")
-		case Active:
-			attr = graph.VertexAttribute("color", "green")
-		case Inconsistent:
-			attr = graph.VertexAttribute("color", "pink")
+type DOTOptions struct {
+	Cluster   bool
+	Collapse  bool
+	Highlight bool
+}
+
+func (c *Correlation) DrawClusteredDOT(w io.Writer, opts DOTOptions, roots ...*Node) error {
+	nodes, edges := subgraph(roots...)
+
+	collapsed := make(map[*Node]int)
+	if opts.Collapse {
+		nodes, edges, collapsed = collapseActive(roots)
+	}
+
+	highlighted := make(map[*Node]bool)
+	if opts.Highlight {
+		highlighted = faultPaths(nodes)
+	}
+
+	clusters := make(map[string][]*Node)
+	clusterOf := make(map[*Node]string)
+	if opts.Cluster {
+		clusterOf = clusterMembers(nodes)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph fibergraph {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+
+	for _, n := range nodes {
+		if id, ok := clusterOf[n]; ok {
+			clusters[id] = append(clusters[id], n)
+			continue
+		}
+		writeDOTNode(bw, "\t", n, collapsed[n], opts.Highlight && !highlighted[n])
+	}
+
+	for _, component := range c.Components {
+		members, ok := clusters[component.ID]
+		if !ok {
+			continue
+		}
+		delete(clusters, component.ID)
+
+		closure := c.closureNodes[component.ID]
+		fmt.Fprintf(bw, "\tsubgraph %s {\n", dotQuote("cluster_"+closure.ID))
+		fmt.Fprintf(bw, "\t\tlabel=%s;\n", dotQuote(fmt.Sprintf("%s %s", closure.Type, closure.ID)))
+		fmt.Fprintf(bw, "\t\tcolor=%s;\n", closure.Status.Color())
+		for _, n := range members {
+			writeDOTNode(bw, "\t\t", n, collapsed[n], opts.Highlight && !highlighted[n])
+		}
+		fmt.Fprintln(bw, "\t}")
+	}
+
+	for _, e := range edges {
+		switch {
+		case !opts.Highlight:
+			fmt.Fprintf(bw, "\t%s -> %s;\n", dotQuote(e[0].ID), dotQuote(e[1].ID))
+		case highlighted[e[0]] && highlighted[e[1]]:
+			fmt.Fprintf(bw, "\t%s -> %s [color=red, penwidth=2];\n", dotQuote(e[0].ID), dotQuote(e[1].ID))
 		default:
-			attr = graph.VertexAttribute("color", "black")
+			fmt.Fprintf(bw, "\t%s -> %s [color=gray];\n", dotQuote(e[0].ID), dotQuote(e[1].ID))
 		}
+	}
+
+	writeDOTLegend(bw)
 
-		_ = g.AddVertex(child.ID, graph.VertexAttribute("label", child.Name), attr)
-		_ = g.AddEdge(n.ID, child.ID)
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+func writeDOTNode(w io.Writer, indent string, n *Node, collapsed int, dimmed bool) {
+	label := n.Name
+	if collapsed > 0 {
+		label = fmt.Sprintf("%s\n(+%d active)", n.Name, collapsed)
+	}
+
+	attrs := fmt.Sprintf("label=%s, color=%s", dotQuote(label), n.Status.Color())
+	if collapsed > 0 {
+		attrs += ", style=\"rounded,bold\""
+	}
+	if dimmed {
+		attrs += ", fontcolor=gray"
+	}
+
+	fmt.Fprintf(w, "%s%s [%s];\n", indent, dotQuote(n.ID), attrs)
+}
+
+func clusterMembers(nodes []*Node) map[*Node]string {
+	result := make(map[*Node]string)
+	for _, n := range nodes {
+		if n.Closure != nil {
+			result[n] = n.Closure.ID
+		}
+	}
+
+	return result
+}
+
+func collapseActive(roots []*Node) ([]*Node, [][2]*Node, map[*Node]int) {
+	active := make(map[*Node]bool)
+	size := make(map[*Node]int)
+
+	var measure func(n *Node, path map[*Node]bool) (bool, int)
+	measure = func(n *Node, path map[*Node]bool) (bool, int) {
+		if _, ok := active[n]; ok {
+			return active[n], size[n]
+		}
+		if path[n] {
+			return false, 0
+		}
+		path[n] = true
+		defer delete(path, n)
+
+		allActive, total := n.Status == Active, 0
+		for _, child := range n.Children {
+			childActive, childSize := measure(child, path)
+			allActive = allActive && childActive
+			total += childSize + 1
+		}
+
+		active[n], size[n] = allActive, total
+		return allActive, total
+	}
+
+	nodes := make([]*Node, 0)
+	edges := make([][2]*Node, 0)
+	collapsed := make(map[*Node]int)
+	seen := make(map[*Node]bool)
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		nodes = append(nodes, n)
+
+		if allActive, total := measure(n, make(map[*Node]bool)); allActive && total > 0 {
+			collapsed[n] = total
+			return
+		}
+
+		for _, child := range n.Children {
+			edges = append(edges, [2]*Node{n, child})
+			walk(child)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root)
+	}
+
+	return nodes, edges, collapsed
+}
+
+func faultPaths(nodes []*Node) map[*Node]bool {
+	result := make(map[*Node]bool)
+	for _, n := range nodes {
+		if n.Status != Alarmed && n.Status != Inconsistent {
+			continue
+		}
+
+		markAncestors(n, result)
+	}
+
+	return result
+}
+
+func markAncestors(n *Node, marked map[*Node]bool) {
+	if marked[n] {
+		return
+	}
+	marked[n] = true
 
-		walk(g, child)
+	for _, parent := range n.Parents {
+		markAncestors(parent, marked)
 	}
 }
@@ -0,0 +1,50 @@
+package correlation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format struct {
+	Name        string
+	ContentType string
+	Draw        func(io.Writer, ...*Node) error
+}
+
+var Formats = []*Format{
+	{Name: "dot", ContentType: "text/vnd.graphviz", Draw: DrawDOT},
+	{Name: "svg", ContentType: "image/svg+xml", Draw: DrawSVG},
+	{Name: "graphml", ContentType: "application/graphml+xml", Draw: DrawGraphML},
+	{Name: "mermaid", ContentType: "text/vnd.mermaid", Draw: DrawMermaid},
+	{Name: "cytoscape", ContentType: "application/vnd.cytoscape+json", Draw: DrawCytoscape},
+}
+
+func ParseFormat(name string) (*Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(f.Name, name) {
+			return f, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown format %q", name)
+}
+
+func FormatByContentType(contentType string) (*Format, bool) {
+	for _, f := range Formats {
+		if strings.EqualFold(f.ContentType, contentType) {
+			return f, true
+		}
+	}
+
+	return nil, false
+}
+
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,103 @@
+package correlation
+
+import "slices"
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func (c *Correlation) Features() []*Feature {
+	result := make([]*Feature, 0)
+	seen := make(map[string]bool)
+
+	nodes := slices.Concat(c.Roots(), c.Result())
+	for _, node := range nodes {
+		if seen[node.ID] {
+			continue
+		}
+		seen[node.ID] = true
+
+		switch node.Type {
+		case CONode, CEONode, CTONode, ONUNode, SensorNode:
+			if node.Position == nil {
+				continue
+			}
+
+			result = append(result, &Feature{
+				Type: "Feature",
+				Geometry: Geometry{
+					Type:        "Point",
+					Coordinates: coordinates(node.Position),
+				},
+				Properties: properties(node),
+			})
+		case SegmentNode:
+			route := c.segmentRoute(node.ID)
+			if route == nil {
+				continue
+			}
+
+			properties := properties(node)
+			properties["fiber_ids"] = c.componentFibers[node.ID]
+
+			result = append(result, &Feature{
+				Type: "Feature",
+				Geometry: Geometry{
+					Type:        "LineString",
+					Coordinates: route,
+				},
+				Properties: properties,
+			})
+		}
+	}
+
+	return result
+}
+
+func (c *Correlation) segmentRoute(segmentID string) [][]float64 {
+	if points := c.routes[segmentID]; len(points) >= 2 {
+		route := make([][]float64, 0, len(points))
+		for _, position := range points {
+			route = append(route, coordinates(position))
+		}
+		return route
+	}
+
+	for _, fiberID := range c.componentFibers[segmentID] {
+		route := make([][]float64, 0, 2)
+		for _, closureID := range c.spanEnds[fiberID] {
+			position, ok := c.positions[closureID]
+			if !ok {
+				continue
+			}
+			route = append(route, coordinates(position))
+		}
+
+		if len(route) >= 2 {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func properties(node *Node) map[string]any {
+	return map[string]any{
+		"id":           node.ID,
+		"component_id": node.ComponentID,
+		"name":         node.Name,
+		"type":         node.Type.String(),
+		"status":       node.Status.String(),
+	}
+}
+
+func coordinates(position *Position) []float64 {
+	return []float64{position.Longitude, position.Latitude}
+}
@@ -0,0 +1,46 @@
+package correlation
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var graphMLKeys = []string{"name", "type", "status", "color"}
+
+func DrawGraphML(w io.Writer, roots ...*Node) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	for _, key := range graphMLKeys {
+		fmt.Fprintf(bw, "\t<key id=%q for=\"node\" attr.name=%q attr.type=\"string\"/>\n", key, key)
+	}
+	fmt.Fprintln(bw, "\t<graph id=\"fibergraph\" edgedefault=\"directed\">")
+
+	nodes, edges := subgraph(roots...)
+	for _, n := range nodes {
+		fmt.Fprintf(bw, "\t\t<node id=\"%s\">", xmlEscape(n.ID))
+		fmt.Fprintf(bw, "<data key=\"name\">%s</data>", xmlEscape(n.Name))
+		fmt.Fprintf(bw, "<data key=\"type\">%s</data>", n.Type)
+		fmt.Fprintf(bw, "<data key=\"status\">%s</data>", n.Status)
+		fmt.Fprintf(bw, "<data key=\"color\">%s</data>", n.Status.Color())
+		fmt.Fprintln(bw, "</node>")
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "\t\t<edge source=\"%s\" target=\"%s\"/>\n", xmlEscape(e[0].ID), xmlEscape(e[1].ID))
+	}
+
+	fmt.Fprintln(bw, "\t</graph>")
+	fmt.Fprintln(bw, "</graphml>")
+
+	return bw.Flush()
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package correlation
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+)
+
+const earthRadius = 6_371_000.0
+
+type Position struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// How the position of an incident was chosen. Device reports only tell
+// which span lost signal, not where along it, so a fiber cut is placed at
+// the midpoint of its span rather than at a computed break point.
+const (
+	PositionSpanMidpoint      = "span_midpoint"
+	PositionUpstreamClosure   = "upstream_closure"
+	PositionDownstreamClosure = "downstream_closure"
+)
+
+// spanMidpoint is the fraction of the span route an incident is placed at.
+const spanMidpoint = 0.5
+
+type Incident struct {
+	Node                   *Node
+	Position               *Position
+	PositionBasis          string
+	DistanceFromUpstream   *float64
+	UpstreamClosure        *Node
+	NearestClosure         *Node
+	NearestClosureDistance float64
+}
+
+func (c *Correlation) Incidents() []*Incident {
+	return c.incidents
+}
+
+func (c *Correlation) loadGeography() {
+	for _, location := range c.Locations {
+		c.positions[location.ComponentID] = &Position{
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		}
+	}
+
+	points := slices.SortedStableFunc(slices.Values(c.Routes), func(a, b *data.RoutePoint) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	for _, point := range points {
+		c.routes[point.SegmentID] = append(c.routes[point.SegmentID], &Position{
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+		})
+	}
+
+	for _, span := range c.Spans {
+		if span.Length != nil {
+			c.lengths[span.FiberID] = *span.Length
+		}
+		c.fiberSegments[span.FiberID] = span.SegmentID
+
+		if span.ClosureIDs == nil {
+			continue
+		}
+		c.spanEnds[span.FiberID] = strings.Split(*span.ClosureIDs, ",")
+	}
+}
+
+func (c *Correlation) locateIncidents() {
+	seen := make(map[string]bool)
+
+	for _, connection := range c.Connections {
+		node, ok := c.connectionNodes[connection.ID]
+		if !ok || seen[node.ID] {
+			continue
+		}
+		seen[node.ID] = true
+
+		if node.Status != Alarmed || hasAlarmedParent(node) {
+			continue
+		}
+
+		c.incidents = append(c.incidents, c.locate(node))
+	}
+}
+
+func (c *Correlation) locate(node *Node) *Incident {
+	incident := &Incident{Node: node}
+
+	fiber := node
+	visited := make(map[string]bool)
+	for fiber != nil && len(c.spanEnds[fiber.ID]) == 0 && !visited[fiber.ID] {
+		visited[fiber.ID] = true
+		fiber = firstParent(fiber)
+	}
+	if fiber == nil || len(c.spanEnds[fiber.ID]) == 0 {
+		return incident
+	}
+
+	upstream, downstream := c.spanClosures(fiber)
+	incident.UpstreamClosure = upstream
+	route := c.spanRoute(fiber, upstream, downstream)
+
+	switch {
+	case fiber != node && downstream != nil:
+		incident.Position = downstream.Position
+		incident.PositionBasis = PositionDownstreamClosure
+	case route != nil:
+		var length float64
+		incident.Position, length = alongRoute(route, spanMidpoint)
+		if l, ok := c.lengths[fiber.ID]; ok {
+			length = l * spanMidpoint
+		}
+		incident.DistanceFromUpstream = &length
+		incident.PositionBasis = PositionSpanMidpoint
+	case upstream != nil && upstream.Position != nil:
+		incident.Position = upstream.Position
+		incident.PositionBasis = PositionUpstreamClosure
+	case downstream != nil && downstream.Position != nil:
+		incident.Position = downstream.Position
+		incident.PositionBasis = PositionDownstreamClosure
+	}
+
+	if incident.Position == nil {
+		incident.PositionBasis = ""
+		return incident
+	}
+
+	incident.NearestClosure, incident.NearestClosureDistance = c.nearestClosure(incident.Position)
+
+	return incident
+}
+
+func (c *Correlation) spanClosures(fiber *Node) (upstream, downstream *Node) {
+	ends := make([]*Node, 0, 2)
+	for _, id := range c.spanEnds[fiber.ID] {
+		closure, ok := c.closureNodes[id]
+		if !ok {
+			continue
+		}
+		ends = append(ends, closure)
+	}
+
+	switch len(ends) {
+	case 0:
+		return nil, nil
+	case 1:
+		return ends[0], nil
+	}
+
+	upstream, downstream = ends[0], ends[1]
+	for _, parent := range fiber.Parents {
+		if slices.Contains(c.componentFibers[downstream.ID], parent.ID) {
+			upstream, downstream = downstream, upstream
+			break
+		}
+	}
+
+	return upstream, downstream
+}
+
+// spanRoute returns the path of the fiber's segment ordered from its upstream
+// end. Without route points it falls back to the straight line between the
+// span's closures.
+func (c *Correlation) spanRoute(fiber, upstream, downstream *Node) []*Position {
+	route := c.routes[c.fiberSegments[fiber.ID]]
+	if len(route) < 2 {
+		route = nil
+		for _, end := range []*Node{upstream, downstream} {
+			if end != nil && end.Position != nil {
+				route = append(route, end.Position)
+			}
+		}
+	}
+
+	if len(route) < 2 {
+		return nil
+	}
+
+	first, last := route[0], route[len(route)-1]
+	switch {
+	case upstream != nil && upstream.Position != nil && haversine(last, upstream.Position) < haversine(first, upstream.Position),
+		downstream != nil && downstream.Position != nil && haversine(first, downstream.Position) < haversine(last, downstream.Position):
+		route = slices.Clone(route)
+		slices.Reverse(route)
+	}
+
+	return route
+}
+
+func (c *Correlation) nearestClosure(position *Position) (*Node, float64) {
+	var nearest *Node
+	distance := math.Inf(1)
+
+	for _, component := range c.Components {
+		closure, ok := c.closureNodes[component.ID]
+		if !ok || closure.Position == nil {
+			continue
+		}
+
+		d := haversine(position, closure.Position)
+		if d < distance {
+			nearest = closure
+			distance = d
+		}
+	}
+
+	if nearest == nil {
+		return nil, 0
+	}
+
+	return nearest, distance
+}
+
+func hasAlarmedParent(node *Node) bool {
+	for _, parent := range node.Parents {
+		if parent.Status == Alarmed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstParent(node *Node) *Node {
+	if len(node.Parents) == 0 {
+		return nil
+	}
+
+	return node.Parents[0]
+}
+
+// alongRoute returns the point at fraction of the route's length and the
+// distance to it from the start of the route.
+func alongRoute(route []*Position, fraction float64) (*Position, float64) {
+	var total float64
+	for i := 1; i < len(route); i++ {
+		total += haversine(route[i-1], route[i])
+	}
+
+	target := total * fraction
+	var walked float64
+	for i := 1; i < len(route); i++ {
+		step := haversine(route[i-1], route[i])
+		if step > 0 && walked+step >= target {
+			return interpolate(route[i-1], route[i], (target-walked)/step), target
+		}
+		walked += step
+	}
+
+	return route[len(route)-1], total
+}
+
+func interpolate(from, to *Position, fraction float64) *Position {
+	return &Position{
+		Latitude:  from.Latitude + (to.Latitude-from.Latitude)*fraction,
+		Longitude: from.Longitude + (to.Longitude-from.Longitude)*fraction,
+	}
+}
+
+func haversine(from, to *Position) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
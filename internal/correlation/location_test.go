@@ -0,0 +1,95 @@
+package correlation
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to Position
+		want     float64
+	}{
+		{name: "same point", from: Position{Latitude: -23.5, Longitude: -46.6}, to: Position{Latitude: -23.5, Longitude: -46.6}, want: 0},
+		{name: "one degree of latitude", from: Position{Latitude: 0, Longitude: 0}, to: Position{Latitude: 1, Longitude: 0}, want: earthRadius * math.Pi / 180},
+		{name: "one degree of longitude on the equator", from: Position{Latitude: 0, Longitude: 10}, to: Position{Latitude: 0, Longitude: 11}, want: earthRadius * math.Pi / 180},
+		{name: "quarter meridian", from: Position{Latitude: 0, Longitude: 0}, to: Position{Latitude: 90, Longitude: 0}, want: earthRadius * math.Pi / 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := haversine(&tt.from, &tt.to)
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlongRoute(t *testing.T) {
+	degree := earthRadius * math.Pi / 180
+
+	tests := []struct {
+		name         string
+		route        []*Position
+		fraction     float64
+		wantPosition Position
+		wantDistance float64
+	}{
+		{
+			name:         "midpoint of a straight span",
+			route:        []*Position{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 2}},
+			fraction:     spanMidpoint,
+			wantPosition: Position{Latitude: 0, Longitude: 1},
+			wantDistance: degree,
+		},
+		{
+			name:         "midpoint lands on a bend",
+			route:        []*Position{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}},
+			fraction:     spanMidpoint,
+			wantPosition: Position{Latitude: 0, Longitude: 1},
+			wantDistance: degree,
+		},
+		{
+			name:         "midpoint past a bend",
+			route:        []*Position{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 3, Longitude: 1}},
+			fraction:     spanMidpoint,
+			wantPosition: Position{Latitude: 1, Longitude: 1},
+			wantDistance: 2 * degree,
+		},
+		{
+			name:         "start",
+			route:        []*Position{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 2}},
+			fraction:     0,
+			wantPosition: Position{Latitude: 0, Longitude: 0},
+			wantDistance: 0,
+		},
+		{
+			name:         "end",
+			route:        []*Position{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 2}},
+			fraction:     1,
+			wantPosition: Position{Latitude: 0, Longitude: 2},
+			wantDistance: 2 * degree,
+		},
+		{
+			name:         "zero length route",
+			route:        []*Position{{Latitude: 5, Longitude: 5}, {Latitude: 5, Longitude: 5}},
+			fraction:     spanMidpoint,
+			wantPosition: Position{Latitude: 5, Longitude: 5},
+			wantDistance: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, distance := alongRoute(tt.route, tt.fraction)
+			if math.Abs(position.Latitude-tt.wantPosition.Latitude) > 1e-6 || math.Abs(position.Longitude-tt.wantPosition.Longitude) > 1e-6 {
+				t.Errorf("got position %+v, want %+v", *position, tt.wantPosition)
+			}
+			if math.Abs(distance-tt.wantDistance) > 1e-3 {
+				t.Errorf("got distance %v, want %v", distance, tt.wantDistance)
+			}
+		})
+	}
+}
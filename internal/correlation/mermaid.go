@@ -0,0 +1,44 @@
+package correlation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func DrawMermaid(w io.Writer, roots ...*Node) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "flowchart TD")
+
+	nodes, edges := subgraph(roots...)
+	ids := make(map[*Node]string, len(nodes))
+	for i, n := range nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(bw, "\t%s[\"%s\"]:::%s\n", ids[n], mermaidLabel(n), mermaidClass(n.Status))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(bw, "\t%s --> %s\n", ids[e[0]], ids[e[1]])
+	}
+
+	for _, status := range legendStatuses {
+		fmt.Fprintf(bw, "\tclassDef %s stroke:%s,stroke-width:2px\n", mermaidClass(status), status.Color())
+	}
+
+	return bw.Flush()
+}
+
+var mermaidReplacer = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+func mermaidClass(s Status) string {
+	return strings.ToLower(s.String())
+}
+
+func mermaidLabel(n *Node) string {
+	return fmt.Sprintf("%s<br/>%s %s<br/>id: %s", mermaidEscape(n.Name), n.Type, n.Status, mermaidEscape(n.ID))
+}
+
+func mermaidEscape(s string) string {
+	return mermaidReplacer.Replace(s)
+}
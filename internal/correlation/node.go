@@ -1,5 +1,7 @@
 package correlation
 
+import "strings"
+
 type (
 	NodeType int
 	Status   int
@@ -53,21 +55,48 @@ func (s Status) String() string {
 	return statusName[s]
 }
 
+func ParseNodeType(name string) (NodeType, bool) {
+	for nt, n := range nodeName {
+		if strings.EqualFold(n, name) {
+			return nt, true
+		}
+	}
+
+	return 0, false
+}
+
+func ParseStatus(name string) (Status, bool) {
+	for s, n := range statusName {
+		if strings.EqualFold(n, name) {
+			return s, true
+		}
+	}
+
+	return 0, false
+}
+
 type Node struct {
-	ID       string
-	Name     string
-	Type     NodeType
-	Status   Status
-	Children []*Node
-	Parents  []*Node
+	ID          string
+	ComponentID string
+	ProjectID   string
+	Closure     *Node
+	Name        string
+	Type        NodeType
+	Status      Status
+	Probability float64
+	Length      float64
+	Position    *Position
+	Children    []*Node
+	Parents     []*Node
 }
 
 func NewNode(id string, name string, nodeType NodeType) *Node {
 	return &Node{
-		ID:     id,
-		Name:   name,
-		Type:   nodeType,
-		Status: Undefined,
+		ID:          id,
+		ComponentID: id,
+		Name:        name,
+		Type:        nodeType,
+		Status:      Undefined,
 	}
 }
 
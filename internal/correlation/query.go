@@ -0,0 +1,95 @@
+package correlation
+
+import (
+	"slices"
+	"strings"
+)
+
+func (c *Correlation) Nodes() []*Node {
+	result := make([]*Node, 0, len(c.connectionNodes)+len(c.topologicNodes))
+	seen := make(map[*Node]bool)
+
+	for _, connection := range c.Connections {
+		node, ok := c.connectionNodes[connection.ID]
+		if !ok || seen[node] {
+			continue
+		}
+		seen[node] = true
+		result = append(result, node)
+	}
+
+	for _, node := range c.topologicNodes {
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		result = append(result, node)
+	}
+
+	return result
+}
+
+func (c *Correlation) Search(query string) []*Node {
+	query = strings.ToLower(query)
+
+	result := make([]*Node, 0)
+	for _, node := range c.Nodes() {
+		if strings.Contains(strings.ToLower(node.ID), query) || strings.Contains(strings.ToLower(node.Name), query) {
+			result = append(result, node)
+		}
+	}
+
+	return result
+}
+
+func Upstream(node *Node) []*Node {
+	previous := map[*Node]*Node{node: nil}
+	queue := []*Node{node}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.Type == CONode && current != node {
+			path := make([]*Node, 0)
+			for n := current; n != node; n = previous[n] {
+				path = append(path, n)
+			}
+			slices.Reverse(path)
+			return path
+		}
+
+		for _, parent := range current.Parents {
+			if _, ok := previous[parent]; ok {
+				continue
+			}
+			previous[parent] = current
+			queue = append(queue, parent)
+		}
+	}
+
+	return make([]*Node, 0)
+}
+
+func Downstream(node *Node) []*Node {
+	nodes, _ := subgraph(node)
+
+	return nodes[1:]
+}
+
+func (n *Node) ParentIDs() []string {
+	return nodeIDs(n.Parents)
+}
+
+func (n *Node) ChildrenIDs() []string {
+	return nodeIDs(n.Children)
+}
+
+func nodeIDs(nodes []*Node) []string {
+	result := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.ID)
+	}
+
+	return result
+}
@@ -0,0 +1,175 @@
+package correlation
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+)
+
+const (
+	svgNodeWidth  = 180
+	svgNodeHeight = 36
+	svgGapX       = 20
+	svgGapY       = 60
+	svgMargin     = 20
+	svgLegendRow  = 20
+	svgMaxLabel   = 24
+)
+
+type svgBox struct {
+	node *Node
+	x, y int
+}
+
+func DrawSVG(w io.Writer, roots ...*Node) error {
+	nodes, edges := subgraph(roots...)
+	layers := layerNodes(nodes, roots)
+
+	legendHeight := svgLegendRow*(len(legendStatuses)+1) + svgMargin
+	boxes := make(map[*Node]*svgBox, len(nodes))
+	width := 0
+	for depth, layer := range layers {
+		for i, n := range layer {
+			boxes[n] = &svgBox{
+				node: n,
+				x:    svgMargin + i*(svgNodeWidth+svgGapX),
+				y:    legendHeight + svgMargin + depth*(svgNodeHeight+svgGapY),
+			}
+		}
+		width = max(width, len(layer)*(svgNodeWidth+svgGapX))
+	}
+	width = max(width+2*svgMargin, 240)
+	height := legendHeight + 2*svgMargin + len(layers)*(svgNodeHeight+svgGapY)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+
+	writeSVGLegend(bw)
+
+	for _, e := range edges {
+		from, to := boxes[e[0]], boxes[e[1]]
+		x1, y1 := from.x+svgNodeWidth/2, from.y+svgNodeHeight
+		x2, y2 := to.x+svgNodeWidth/2, to.y
+		fmt.Fprintf(bw, `<path d="M%d %d C%d %d %d %d %d %d" fill="none" stroke="#888"/>`+"\n", x1, y1, x1, y1+svgGapY/2, x2, y2-svgGapY/2, x2, y2)
+	}
+
+	for _, n := range nodes {
+		box := boxes[n]
+		fmt.Fprintf(bw, `<g id="%s"><title>%s (%s)</title>`, html.EscapeString(n.ID), html.EscapeString(n.Name), n.Status)
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="white" stroke="%s" stroke-width="2"/>`, box.x, box.y, svgNodeWidth, svgNodeHeight, n.Status.Color())
+		fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">%s</text></g>`+"\n", box.x+svgNodeWidth/2, box.y+svgNodeHeight/2+4, html.EscapeString(truncate(n.Name, svgMaxLabel)))
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+
+	return bw.Flush()
+}
+
+func writeSVGLegend(w io.Writer) {
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-weight="bold">Legend</text>`+"\n", svgMargin, svgMargin+svgLegendRow/2)
+	for i, status := range legendStatuses {
+		y := svgMargin + (i+1)*svgLegendRow
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="14" height="14" fill="white" stroke="%s" stroke-width="2"/>`, svgMargin, y, status.Color())
+		fmt.Fprintf(w, `<text x="%d" y="%d">%s</text>`+"\n", svgMargin+20, y+11, status)
+	}
+}
+
+func layerNodes(nodes []*Node, roots []*Node) [][]*Node {
+	depth := make(map[*Node]int, len(nodes))
+	inDegree := make(map[*Node]int, len(nodes))
+	for _, n := range nodes {
+		for _, child := range n.Children {
+			inDegree[child]++
+		}
+	}
+
+	queue := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if inDegree[n] == 0 || slices.Contains(roots, n) {
+			queue = append(queue, n)
+		}
+	}
+
+	visited := make(map[*Node]bool, len(nodes))
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+
+		for _, child := range n.Children {
+			if visited[child] {
+				continue
+			}
+			depth[child] = max(depth[child], depth[n]+1)
+			inDegree[child]--
+			if inDegree[child] <= 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	for _, n := range nodes {
+		if visited[n] {
+			continue
+		}
+		for _, parent := range n.Parents {
+			if d, ok := depth[parent]; ok {
+				depth[n] = max(depth[n], d+1)
+			}
+		}
+	}
+
+	layers := make([][]*Node, 0)
+	for _, n := range nodes {
+		d := depth[n]
+		for len(layers) <= d {
+			layers = append(layers, make([]*Node, 0))
+		}
+		layers[d] = append(layers[d], n)
+	}
+
+	for i := 1; i < len(layers); i++ {
+		position := make(map[*Node]int, len(layers[i-1]))
+		for j, n := range layers[i-1] {
+			position[n] = j
+		}
+
+		slices.SortStableFunc(layers[i], func(a, b *Node) int {
+			return barycenter(a, position) - barycenter(b, position)
+		})
+	}
+
+	return layers
+}
+
+func barycenter(n *Node, position map[*Node]int) int {
+	sum, count := 0, 0
+	for _, parent := range n.Parents {
+		if p, ok := position[parent]; ok {
+			sum += p
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return sum * 100 / count
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-1]) + "…"
+}
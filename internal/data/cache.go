@@ -0,0 +1,177 @@
+package data
+
+import (
+	"context"
+	"expvar"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var cacheMetrics = expvar.NewMap("topology_cache")
+
+func init() {
+	cacheMetrics.Set("hit_rate", expvar.Func(func() any {
+		hits := metricValue("hits")
+		total := hits + metricValue("waits") + metricValue("misses")
+		if total == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(total)
+	}))
+}
+
+type cacheEntry struct {
+	tenantID   string
+	projectIDs []string
+	ready      chan struct{}
+	topology   *Topology
+	err        error
+	expires    time.Time
+}
+
+// TopologyCache keeps the topology of each tenant and project set in memory
+// until its TTL expires or it is invalidated. Concurrent misses on the same
+// key share a single load, which is not tied to any caller's cancellation.
+// Cached topologies must be treated as read-only.
+type TopologyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+func NewTopologyCache(ttl time.Duration) *TopologyCache {
+	return &TopologyCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+type Invalidator interface {
+	Invalidate(tenantID, projectID string) int
+}
+
+// CachedSource serves the topologies of a TopologySource through a cache.
+type CachedSource struct {
+	TopologySource
+	cache *TopologyCache
+}
+
+func NewCachedSource(source TopologySource, ttl time.Duration) *CachedSource {
+	return &CachedSource{
+		TopologySource: source,
+		cache:          NewTopologyCache(ttl),
+	}
+}
+
+func (s *CachedSource) LoadTopology(ctx context.Context, tenantID string, projectIDs ...string) (*Topology, error) {
+	return s.cache.Get(ctx, tenantID, projectIDs, func(ctx context.Context) (*Topology, error) {
+		return s.TopologySource.LoadTopology(ctx, tenantID, projectIDs...)
+	})
+}
+
+func (s *CachedSource) Invalidate(tenantID, projectID string) int {
+	return s.cache.Invalidate(tenantID, projectID)
+}
+
+func (tc *TopologyCache) Get(ctx context.Context, tenantID string, projectIDs []string, load func(context.Context) (*Topology, error)) (*Topology, error) {
+	key := cacheKey(tenantID, projectIDs)
+
+	tc.mu.Lock()
+	entry, ok := tc.entries[key]
+	switch {
+	case ok && !isReady(entry):
+		cacheMetrics.Add("waits", 1)
+	case ok && entry.err == nil && time.Now().Before(entry.expires):
+		cacheMetrics.Add("hits", 1)
+	default:
+		entry = &cacheEntry{tenantID: tenantID, projectIDs: projectIDs, ready: make(chan struct{})}
+		tc.entries[key] = entry
+		cacheMetrics.Add("misses", 1)
+
+		go tc.load(context.WithoutCancel(ctx), key, entry, load)
+	}
+	tc.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.topology, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load fills entry on behalf of every caller waiting for it, bounded by its
+// own timeout rather than by the context of the caller that missed.
+func (tc *TopologyCache) load(ctx context.Context, key string, entry *cacheEntry, load func(context.Context) (*Topology, error)) {
+	ctx, cancel := context.WithTimeout(ctx, topologyTimeout)
+	defer cancel()
+
+	start := time.Now()
+	entry.topology, entry.err = load(ctx)
+	entry.expires = time.Now().Add(tc.ttl)
+	close(entry.ready)
+
+	if entry.err != nil {
+		tc.remove(key, entry)
+		return
+	}
+
+	cacheMetrics.Add("rebuilds", 1)
+	cacheMetrics.AddFloat("rebuild_seconds", time.Since(start).Seconds())
+}
+
+// Invalidate drops every cached topology of the tenant that includes
+// projectID, or all of the tenant's topologies when projectID is empty.
+func (tc *TopologyCache) Invalidate(tenantID, projectID string) int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	removed := 0
+	for key, entry := range tc.entries {
+		if entry.tenantID != tenantID {
+			continue
+		}
+		if projectID != "" && !slices.Contains(entry.projectIDs, projectID) {
+			continue
+		}
+		delete(tc.entries, key)
+		removed++
+	}
+
+	cacheMetrics.Add("invalidations", int64(removed))
+
+	return removed
+}
+
+func (tc *TopologyCache) remove(key string, entry *cacheEntry) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.entries[key] == entry {
+		delete(tc.entries, key)
+	}
+}
+
+func isReady(entry *cacheEntry) bool {
+	select {
+	case <-entry.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+func cacheKey(tenantID string, projectIDs []string) string {
+	return tenantID + "/" + strings.Join(slices.Sorted(slices.Values(projectIDs)), ",")
+}
+
+func metricValue(name string) int64 {
+	v, ok := cacheMetrics.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTopologyCacheGet(t *testing.T) {
+	errLoad := errors.New("load failed")
+	closed := make(chan struct{})
+	close(closed)
+
+	tests := []struct {
+		name     string
+		entry    *cacheEntry
+		wantLoad bool
+	}{
+		{
+			name:     "miss",
+			wantLoad: true,
+		},
+		{
+			name:  "hit",
+			entry: &cacheEntry{ready: closed, topology: &Topology{Version: "cached"}, expires: time.Now().Add(time.Hour)},
+		},
+		{
+			name:     "expired",
+			entry:    &cacheEntry{ready: closed, topology: &Topology{Version: "cached"}, expires: time.Now().Add(-time.Second)},
+			wantLoad: true,
+		},
+		{
+			name:     "failed load not yet removed",
+			entry:    &cacheEntry{ready: closed, err: errLoad, expires: time.Now().Add(time.Hour)},
+			wantLoad: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := NewTopologyCache(time.Hour)
+			if tt.entry != nil {
+				tc.entries[cacheKey("acme", []string{"1"})] = tt.entry
+			}
+
+			loaded := false
+			topology, err := tc.Get(context.Background(), "acme", []string{"1"}, func(context.Context) (*Topology, error) {
+				loaded = true
+				return &Topology{Version: "loaded"}, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if loaded != tt.wantLoad {
+				t.Errorf("got load %v, want %v", loaded, tt.wantLoad)
+			}
+
+			want := "cached"
+			if tt.wantLoad {
+				want = "loaded"
+			}
+			if topology.Version != want {
+				t.Errorf("got topology %q, want %q", topology.Version, want)
+			}
+		})
+	}
+}
@@ -3,8 +3,6 @@ package data
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
 
 	_ "embed"
 )
@@ -13,45 +11,14 @@ import (
 var componentQuery string
 
 type Component struct {
-	ID       string
-	FiberIDs *string
-	Type     string
+	ID       string  `json:"id" yaml:"id"`
+	FiberIDs *string `json:"fiber_ids" yaml:"fiber_ids"`
+	Type     string  `json:"type" yaml:"type"`
 }
 
-type ComponentModel struct {
-	DB *sql.DB
-}
-
-func (m *ComponentModel) GetAll(tenantID, projectID string) ([]*Component, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	tx, err := m.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("begin %w", err)
-	}
-	defer tx.Rollback()
-
-	err = setSchema(ctx, tx, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("set schema %w", err)
-	}
-
-	components, err := getComponents(ctx, tx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("get component %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("commit %w", err)
-	}
-
-	return components, nil
-}
-
-func getComponents(ctx context.Context, tx *sql.Tx, projectID string) ([]*Component, error) {
-	rows, err := tx.QueryContext(ctx, componentQuery, projectID, projectID)
+func getComponents(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Component, error) {
+	query, args := projectQuery(componentQuery, 2, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,6 @@
 SELECT 
 	p.port_splice_closure_network_component_id,
-	GROUP_CONCAT(p.port_network_component_id),
+	GROUP_CONCAT(DISTINCT p.port_network_component_id),
 	CASE
 		WHEN ceo.ceo_network_component_id IS NOT NULL THEN 'CEO'
 		WHEN cto.cto_network_component_id IS NOT NULL THEN 'CTO'
@@ -18,7 +18,7 @@ FROM
 	LEFT OUTER JOIN onu ON onu.onu_network_component_id = nc.nc_id
 WHERE
 	p.optical_signal_direction = 'TX'
-	AND pnc.pnc_project_id = ?
+	AND pnc.pnc_project_id IN (%[1]s)
 GROUP BY
 	p.port_splice_closure_network_component_id
 
@@ -26,7 +26,7 @@ UNION ALL
 
 SELECT 
 	f.fiber_segment_id,
-	GROUP_CONCAT(f.fiber_id),
+	GROUP_CONCAT(DISTINCT f.fiber_id),
 	'Segment'
 FROM
 	fiber f
@@ -35,6 +35,6 @@ FROM
 	LEFT OUTER JOIN network_component nc ON nc.nc_id = c.cable_id
 	LEFT OUTER JOIN project_network_component pnc ON pnc_network_component_id = nc.nc_id
 WHERE
-	pnc.pnc_project_id = ?
+	pnc.pnc_project_id IN (%[1]s)
 GROUP BY
 	f.fiber_segment_id;
@@ -3,8 +3,7 @@ package data
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
+	"strings"
 
 	_ "embed"
 )
@@ -12,49 +11,20 @@ import (
 //go:embed connection.sql
 var connectionQuery string
 
-type Connection struct {
-	ID          string
-	Name        string
-	ParentIDs   *string
-	ChildrenIDs *string
-	Type        string
-}
-
-type ConnectionModel struct {
-	DB *sql.DB
-}
-
-func (m *ConnectionModel) GetAll(tenantID, projectID string) ([]*Connection, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	tx, err := m.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("begin %w", err)
-	}
-	defer tx.Rollback()
-
-	err = setSchema(ctx, tx, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("set schema %w", err)
-	}
+// ConnectionTypes lists the connection types of the OSP database.
+var ConnectionTypes = []string{"CO", "DIO", "Fiber", "Splitter"}
 
-	connections, err := getConnections(ctx, tx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("get connection %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("commit %w", err)
-	}
-
-	return connections, nil
+type Connection struct {
+	ID          string  `json:"id" yaml:"id"`
+	Name        string  `json:"name" yaml:"name"`
+	ParentIDs   *string `json:"parent_ids" yaml:"parent_ids"`
+	ChildrenIDs *string `json:"children_ids" yaml:"children_ids"`
+	Type        string  `json:"type" yaml:"type"`
 }
 
-func getConnections(ctx context.Context, tx *sql.Tx, projectID string) ([]*Connection, error) {
-	args := []any{projectID, projectID, projectID, projectID, projectID, projectID, projectID, projectID}
-	rows, err := tx.QueryContext(ctx, connectionQuery, args...)
+func getConnections(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Connection, error) {
+	query, args := projectQuery(connectionQuery, 8, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -83,3 +53,14 @@ func getConnections(ctx context.Context, tx *sql.Tx, projectID string) ([]*Conne
 
 	return connections, nil
 }
+
+// JoinIDs packs IDs into a comma-joined column the way the OSP database
+// returns them, nil when there are none.
+func JoinIDs(ids []string) *string {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	joined := strings.Join(ids, ",")
+	return &joined
+}
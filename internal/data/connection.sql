@@ -2,9 +2,9 @@ SELECT
 	p1.port_network_component_id,
 	nc.nc_name,
 	CASE
-		WHEN d.dio_network_component_id IS NOT NULL THEN d.dio_co_network_component_id ELSE GROUP_CONCAT(p2.port_network_component_id)
+		WHEN d.dio_network_component_id IS NOT NULL THEN d.dio_co_network_component_id ELSE GROUP_CONCAT(DISTINCT p2.port_network_component_id)
 	END AS parent,
-	GROUP_CONCAT(p3.port_network_component_id) AS children,
+	GROUP_CONCAT(DISTINCT p3.port_network_component_id) AS children,
 	CASE
 		WHEN f.fiber_id IS NOT NULL THEN 'Fiber'
 		WHEN s.splitter_network_component_id IS NOT NULL THEN 'Splitter'
@@ -50,12 +50,12 @@ WHERE
 	AND o.onu_network_component_id IS NULL
     AND cto2.cto_network_component_id IS NULL
 	AND (
-		pnc1.pnc_project_id = ?
-		OR pnc2.pnc_project_id = ?
-		OR pnc3.pnc_project_id = ?
-		OR pnc4.pnc_project_id = ?
-		OR pnc5.pnc_project_id = ?
-		OR pnc6.pnc_project_id = ?
+		pnc1.pnc_project_id IN (%[1]s)
+		OR pnc2.pnc_project_id IN (%[1]s)
+		OR pnc3.pnc_project_id IN (%[1]s)
+		OR pnc4.pnc_project_id IN (%[1]s)
+		OR pnc5.pnc_project_id IN (%[1]s)
+		OR pnc6.pnc_project_id IN (%[1]s)
 	)
 GROUP BY
 	p1.port_network_component_id
@@ -64,7 +64,7 @@ HAVING
 	
 UNION ALL
 
-SELECT
+SELECT DISTINCT
 	p1.port_network_component_id AS port_network_component_id,
     'no_name',
 	p2.port_network_component_id AS parent,
@@ -90,7 +90,7 @@ FROM
 	LEFT OUTER JOIN splitter s1 ON s1.splitter_network_component_id = p1.port_network_component_id
 	LEFT OUTER JOIN splitter s2 ON s2.splitter_network_component_id = p2.port_network_component_id
 WHERE
-	pnc.pnc_project_id = ?
+	pnc.pnc_project_id IN (%[1]s)
 
 UNION ALL
 
@@ -98,7 +98,7 @@ SELECT
 	c.co_network_component_id,
 	nc.nc_name,
 	null,
-	group_concat(d.dio_network_component_id),
+	group_concat(DISTINCT d.dio_network_component_id),
 	'CO'
 FROM
 	co c
@@ -106,6 +106,6 @@ FROM
 	LEFT OUTER JOIN dio d ON d.dio_co_network_component_id = c.co_network_component_id
 	LEFT OUTER JOIN project_network_component pnc ON pnc.pnc_network_component_id = nc.nc_id
 WHERE
-	pnc.pnc_project_id = ?
+	pnc.pnc_project_id IN (%[1]s)
 GROUP BY 
 	c.co_network_component_id;
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Device struct {
+	ID        string `json:"id" yaml:"id"`
+	Kind      string `json:"kind" yaml:"kind"`
+	ProjectID string `json:"project_id" yaml:"project_id"`
+}
+
+type DeviceModel struct {
+	DB *sql.DB
+}
+
+func (m *DeviceModel) GetAll(ctx context.Context, tenantID string) ([]*Device, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin %w", dbError(err))
+	}
+	defer tx.Rollback()
+
+	err = setSchema(ctx, tx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("set schema %w", dbError(err))
+	}
+
+	devices, err := getDevices(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("get devices %w", dbError(err))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("commit %w", dbError(err))
+	}
+
+	return devices, nil
+}
+
+func getDevices(ctx context.Context, tx *sql.Tx) ([]*Device, error) {
+	query := `
+		SELECT
+			s.sensor_deveui,
+			'SENSOR',
+			pnc.pnc_project_id
+		FROM
+			sensor s
+			JOIN project_network_component pnc ON pnc.pnc_network_component_id = s.sensor_network_component_id
+
+		UNION ALL
+
+		SELECT
+			o.onu_gpon_serial_number,
+			'ONU',
+			pnc.pnc_project_id
+		FROM
+			onu o
+			JOIN project_network_component pnc ON pnc.pnc_network_component_id = o.onu_network_component_id;
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := make([]*Device, 0)
+	for rows.Next() {
+		var device Device
+		err := rows.Scan(
+			&device.ID,
+			&device.Kind,
+			&device.ProjectID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, &device)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
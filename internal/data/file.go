@@ -0,0 +1,57 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dataset is the file representation of a MemorySource: the topology of
+// every project, grouped by tenant.
+type Dataset struct {
+	Tenants map[string]map[string]*Topology `json:"tenants" yaml:"tenants"`
+}
+
+// NewFileSource loads a JSON or YAML dataset, chosen by the file extension,
+// into a MemorySource.
+func NewFileSource(path string) (*MemorySource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var dataset Dataset
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&dataset)
+	case ".yaml", ".yml":
+		err = yaml.NewDecoder(f).Decode(&dataset)
+	default:
+		return nil, fmt.Errorf("unsupported dataset file %q, want .json, .yaml or .yml", path)
+	}
+	if errors.Is(err, io.EOF) || (err == nil && len(dataset.Tenants) == 0) {
+		return nil, fmt.Errorf("dataset %s has no tenants", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	source := NewMemorySource()
+	for tenantID, projects := range dataset.Tenants {
+		for projectID, topology := range projects {
+			if topology == nil {
+				topology = &Topology{}
+			}
+			source.Add(tenantID, projectID, topology)
+		}
+	}
+
+	return source, nil
+}
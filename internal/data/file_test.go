@@ -0,0 +1,116 @@
+package data
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDataset(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestNewFileSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantErr  string
+		projects []string
+	}{
+		{
+			name: "json",
+			file: "dataset.json",
+			content: `{"tenants": {"acme": {
+				"1": {"connections": [{"id": "F-1", "name": "F-1", "type": "Fiber"}]},
+				"2": {}
+			}}}`,
+			projects: []string{"1", "2"},
+		},
+		{
+			name: "yaml",
+			file: "dataset.yaml",
+			content: `tenants:
+  acme:
+    "1":
+      connections:
+        - id: F-1
+          name: F-1
+          type: Fiber
+    "2":
+`,
+			projects: []string{"1", "2"},
+		},
+		{
+			name:    "empty json",
+			file:    "dataset.json",
+			content: "",
+			wantErr: "has no tenants",
+		},
+		{
+			name:    "empty yaml",
+			file:    "dataset.yml",
+			content: "",
+			wantErr: "has no tenants",
+		},
+		{
+			name:    "no tenants",
+			file:    "dataset.json",
+			content: `{"tenants": {}}`,
+			wantErr: "has no tenants",
+		},
+		{
+			name:    "malformed",
+			file:    "dataset.json",
+			content: `{"tenants": `,
+			wantErr: "decode",
+		},
+		{
+			name:    "unsupported extension",
+			file:    "dataset.txt",
+			content: `{}`,
+			wantErr: "unsupported dataset file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := NewFileSource(writeDataset(t, tt.file, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			projects, err := source.Projects(context.Background(), "acme")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(projects, ",") != strings.Join(tt.projects, ",") {
+				t.Errorf("got projects %v, want %v", projects, tt.projects)
+			}
+
+			topology, err := source.LoadTopology(context.Background(), "acme", "1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(topology.Connections) != 1 || topology.Connections[0].ID != "F-1" {
+				t.Errorf("got connections %+v, want F-1", topology.Connections)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Location struct {
+	ComponentID string  `json:"component_id" yaml:"component_id"`
+	Latitude    float64 `json:"latitude" yaml:"latitude"`
+	Longitude   float64 `json:"longitude" yaml:"longitude"`
+}
+
+func getLocations(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Location, error) {
+	query := `
+		SELECT DISTINCT
+			nc.nc_id,
+			nc.nc_latitude,
+			nc.nc_longitude
+		FROM
+			network_component nc
+			LEFT OUTER JOIN project_network_component pnc ON pnc.pnc_network_component_id = nc.nc_id
+		WHERE
+			pnc.pnc_project_id IN (%s)
+			AND nc.nc_latitude IS NOT NULL
+			AND nc.nc_longitude IS NOT NULL;
+	`
+
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]*Location, 0)
+	for rows.Next() {
+		var location Location
+		err := rows.Scan(
+			&location.ComponentID,
+			&location.Latitude,
+			&location.Longitude,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, &location)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
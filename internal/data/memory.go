@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// MemorySource keeps topologies in memory, keyed by tenant and project.
+type MemorySource struct {
+	mu      sync.RWMutex
+	tenants map[string]map[string]*Topology
+}
+
+func NewMemorySource() *MemorySource {
+	return &MemorySource{
+		tenants: make(map[string]map[string]*Topology),
+	}
+}
+
+func (s *MemorySource) Add(tenantID, projectID string, topology *Topology) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects, ok := s.tenants[tenantID]
+	if !ok {
+		projects = make(map[string]*Topology)
+		s.tenants[tenantID] = projects
+	}
+	projects[projectID] = topology
+}
+
+func (s *MemorySource) LoadTopology(_ context.Context, tenantID string, projectIDs ...string) (*Topology, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects, ok := s.tenants[tenantID]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+
+	// Rows shared by several projects are kept once, as the database query
+	// over all of them returns them.
+	var topology Topology
+	seen := make(map[string]bool)
+	for _, projectID := range projectIDs {
+		project, ok := projects[projectID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectID)
+		}
+
+		topology.Connections = mergeRows(topology.Connections, project.Connections, seen, func(c *Connection) string { return "connection/" + c.ID })
+		topology.Sensors = mergeRows(topology.Sensors, project.Sensors, seen, func(s *Sensor) string { return "sensor/" + s.ID })
+		topology.ONUs = mergeRows(topology.ONUs, project.ONUs, seen, func(o *ONU) string { return "onu/" + o.ID })
+		topology.Components = mergeRows(topology.Components, project.Components, seen, func(c *Component) string { return "component/" + c.ID })
+		topology.Spans = mergeRows(topology.Spans, project.Spans, seen, func(s *Span) string { return "span/" + s.FiberID + "/" + s.SegmentID })
+		topology.Locations = mergeRows(topology.Locations, project.Locations, seen, func(l *Location) string { return "location/" + l.ComponentID })
+		topology.Routes = mergeRows(topology.Routes, project.Routes, seen, func(p *RoutePoint) string { return fmt.Sprintf("route/%s/%d", p.SegmentID, p.Sequence) })
+		topology.Owners = mergeRows(topology.Owners, projectOwners(projectID, project), seen, func(o *Owner) string { return "owner/" + o.NetworkComponentID })
+	}
+
+	version, err := topologyVersion(&topology)
+	if err != nil {
+		return nil, err
+	}
+	topology.Version = version
+
+	return &topology, nil
+}
+
+func (s *MemorySource) Projects(_ context.Context, tenantID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects, ok := s.tenants[tenantID]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+
+	return slices.Sorted(maps.Keys(projects)), nil
+}
+
+func (s *MemorySource) Devices(_ context.Context, tenantID string) ([]*Device, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects, ok := s.tenants[tenantID]
+	if !ok {
+		return nil, ErrTenantNotFound
+	}
+
+	devices := make([]*Device, 0)
+	for _, projectID := range slices.Sorted(maps.Keys(projects)) {
+		for _, sensor := range projects[projectID].Sensors {
+			devices = append(devices, &Device{ID: sensor.DevEUI, Kind: "SENSOR", ProjectID: projectID})
+		}
+		for _, onu := range projects[projectID].ONUs {
+			devices = append(devices, &Device{ID: onu.SerialNumber, Kind: "ONU", ProjectID: projectID})
+		}
+	}
+
+	return devices, nil
+}
+
+// projectOwners attributes every component, connection and device of a
+// stored project topology to projectID.
+func projectOwners(projectID string, topology *Topology) []*Owner {
+	ids := make([]string, 0)
+	for _, component := range topology.Components {
+		ids = append(ids, component.ID)
+	}
+	for _, connection := range topology.Connections {
+		ids = append(ids, connection.ID)
+	}
+	for _, sensor := range topology.Sensors {
+		ids = append(ids, sensor.ID)
+	}
+	for _, onu := range topology.ONUs {
+		ids = append(ids, onu.ID)
+	}
+
+	owners := make([]*Owner, 0, len(ids))
+	for _, id := range ids {
+		owners = append(owners, &Owner{NetworkComponentID: id, ProjectID: projectID})
+	}
+
+	return owners
+}
+
+// mergeRows appends the rows whose key has not been seen yet.
+func mergeRows[T any](rows, more []T, seen map[string]bool, key func(T) string) []T {
+	for _, row := range more {
+		k := key(row)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		rows = append(rows, row)
+	}
+
+	return rows
+}
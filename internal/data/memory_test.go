@@ -0,0 +1,135 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func testMemorySource() *MemorySource {
+	source := NewMemorySource()
+	source.Add("acme", "1", &Topology{
+		Connections: []*Connection{{ID: "F-1", Name: "F-1", Type: "Fiber"}},
+		Sensors:     []*Sensor{{ID: "S-1", DevEUI: "0000000000000001", FiberID: "F-1"}},
+		Components:  []*Component{{ID: "CTO-1", Type: "CTO"}},
+	})
+	source.Add("acme", "2", &Topology{
+		Connections: []*Connection{{ID: "F-2", Name: "F-2", Type: "Fiber"}},
+		ONUs:        []*ONU{{ID: "O-1", SerialNumber: "ABCD00000001", FiberID: "F-2"}},
+	})
+	source.Add("acme", "3", &Topology{
+		Connections: []*Connection{{ID: "F-3", Name: "F-3", Type: "Fiber"}},
+		Components:  []*Component{{ID: "CTO-1", Type: "CTO"}},
+		Locations:   []*Location{{ComponentID: "CTO-1"}},
+	})
+	source.Add("acme", "4", &Topology{
+		Connections: []*Connection{{ID: "F-3", Name: "F-3", Type: "Fiber"}},
+		Components:  []*Component{{ID: "CTO-1", Type: "CTO"}},
+		Locations:   []*Location{{ComponentID: "CTO-1"}},
+	})
+
+	return source
+}
+
+func TestMemorySourceLoadTopology(t *testing.T) {
+	tests := []struct {
+		name        string
+		tenantID    string
+		projectIDs  []string
+		wantErr     error
+		connections int
+		sensors     int
+		onus        int
+		components  int
+		locations   int
+		owners      map[string]string
+	}{
+		{
+			name:        "single project",
+			tenantID:    "acme",
+			projectIDs:  []string{"1"},
+			connections: 1,
+			sensors:     1,
+			components:  1,
+			owners:      map[string]string{"F-1": "1", "S-1": "1", "CTO-1": "1"},
+		},
+		{
+			name:        "merged projects",
+			tenantID:    "acme",
+			projectIDs:  []string{"1", "2"},
+			connections: 2,
+			sensors:     1,
+			onus:        1,
+			components:  1,
+			owners:      map[string]string{"F-1": "1", "S-1": "1", "CTO-1": "1", "F-2": "2", "O-1": "2"},
+		},
+		{
+			name:        "shared component",
+			tenantID:    "acme",
+			projectIDs:  []string{"3", "4"},
+			connections: 1,
+			components:  1,
+			locations:   1,
+			owners:      map[string]string{"F-3": "3", "CTO-1": "3"},
+		},
+		{
+			name:       "unknown tenant",
+			tenantID:   "globex",
+			projectIDs: []string{"1"},
+			wantErr:    ErrTenantNotFound,
+		},
+		{
+			name:       "unknown project",
+			tenantID:   "acme",
+			projectIDs: []string{"1", "5"},
+			wantErr:    ErrProjectNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology, err := testMemorySource().LoadTopology(context.Background(), tt.tenantID, tt.projectIDs...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := len(topology.Connections); got != tt.connections {
+				t.Errorf("got %d connections, want %d", got, tt.connections)
+			}
+			if got := len(topology.Sensors); got != tt.sensors {
+				t.Errorf("got %d sensors, want %d", got, tt.sensors)
+			}
+			if got := len(topology.ONUs); got != tt.onus {
+				t.Errorf("got %d onus, want %d", got, tt.onus)
+			}
+			if got := len(topology.Components); got != tt.components {
+				t.Errorf("got %d components, want %d", got, tt.components)
+			}
+			if got := len(topology.Locations); got != tt.locations {
+				t.Errorf("got %d locations, want %d", got, tt.locations)
+			}
+			if got := len(topology.Owners); got != len(tt.owners) {
+				t.Errorf("got %d owners, want %d", got, len(tt.owners))
+			}
+			if topology.Version == "" {
+				t.Error("got empty version")
+			}
+
+			owners := make(map[string]string)
+			for _, owner := range topology.Owners {
+				owners[owner.NetworkComponentID] = owner.ProjectID
+			}
+			for id, want := range tt.owners {
+				if owners[id] != want {
+					t.Errorf("got owner %q for %s, want %q", owners[id], id, want)
+				}
+			}
+		})
+	}
+}
@@ -3,26 +3,70 @@ package data
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
-var ErrRecordNotFound = errors.New("record not found")
+const unknownDatabase = 1049
+
+var (
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrTenantNotFound  = errors.New("tenant not found")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrUnavailable     = errors.New("database unavailable")
+	ErrTimeout         = errors.New("database timeout")
+)
 
 type Models struct {
-	Component  ComponentModel
-	Connection ConnectionModel
-	Sensor     SensorModel
-	ONU        ONUModel
+	Device  DeviceModel
+	Project ProjectModel
+
+	db *sql.DB
 }
 
 func NewModels(db *sql.DB) *Models {
 	return &Models{
-		Component:  ComponentModel{DB: db},
-		Connection: ConnectionModel{DB: db},
-		Sensor:     SensorModel{DB: db},
-		ONU:        ONUModel{DB: db},
+		Device:  DeviceModel{DB: db},
+		Project: ProjectModel{DB: db},
+		db:      db,
+	}
+}
+
+// projectQuery fills the IN lists of query with one placeholder per project.
+// Each of the query's uses lists the projects again, so the arguments repeat
+// projectIDs uses times.
+func projectQuery(query string, uses int, projectIDs []string) (string, []any) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(projectIDs)), ",")
+
+	args := make([]any, 0, uses*len(projectIDs))
+	for range uses {
+		for _, projectID := range projectIDs {
+			args = append(args, projectID)
+		}
 	}
+
+	return fmt.Sprintf(query, placeholders), args
+}
+
+func dbError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == unknownDatabase:
+		return fmt.Errorf("%w: %w", ErrTenantNotFound, err)
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn), errors.As(err, &netErr):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return err
 }
 
 func setSchema(ctx context.Context, tx *sql.Tx, tenantID string) error {
@@ -3,52 +3,18 @@ package data
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
 )
 
 type ONU struct {
-	ID           string
-	SerialNumber string
-	Status       string
-	FiberID      string
+	ID           string `json:"id" yaml:"id"`
+	SerialNumber string `json:"serial_number" yaml:"serial_number"`
+	Status       string `json:"status" yaml:"status"`
+	FiberID      string `json:"fiber_id" yaml:"fiber_id"`
 }
 
-type ONUModel struct {
-	DB *sql.DB
-}
-
-func (m *ONUModel) GetAll(tenantID, projectID string) ([]*ONU, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	tx, err := m.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("begin %w", err)
-	}
-	defer tx.Rollback()
-
-	err = setSchema(ctx, tx, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("set schema %w", err)
-	}
-
-	onus, err := getONUs(ctx, tx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("get onus %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("commit %w", err)
-	}
-
-	return onus, nil
-}
-
-func getONUs(ctx context.Context, tx *sql.Tx, projectID string) ([]*ONU, error) {
+func getONUs(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*ONU, error) {
 	query := `
-		SELECT
+		SELECT DISTINCT
 			o.onu_network_component_id,
 			o.onu_gpon_serial_number,
 			o.onu_operational_state,
@@ -60,10 +26,11 @@ func getONUs(ctx context.Context, tx *sql.Tx, projectID string) ([]*ONU, error)
 			LEFT OUTER JOIN port p1 ON p1.port_network_component_id = nc.nc_id
 			LEFT OUTER JOIN port p2 ON p2.port_connected_to_port_id = p1.port_id
 		WHERE
-			pnc.pnc_project_id = ?;
+			pnc.pnc_project_id IN (%s);
 	`
 
-	rows, err := tx.QueryContext(ctx, query, projectID)
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Owner records which project a network component belongs to, so results of
+// a run merged over several projects can be reported to each of them.
+type Owner struct {
+	NetworkComponentID string `json:"network_component_id" yaml:"network_component_id"`
+	ProjectID          string `json:"project_id" yaml:"project_id"`
+}
+
+func getOwners(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Owner, error) {
+	query := `
+		SELECT
+			pnc.pnc_network_component_id,
+			pnc.pnc_project_id
+		FROM
+			project_network_component pnc
+		WHERE
+			pnc.pnc_project_id IN (%[1]s)
+
+		UNION ALL
+
+		SELECT
+			s.segment_id,
+			pnc.pnc_project_id
+		FROM
+			segment s
+			JOIN project_network_component pnc ON pnc.pnc_network_component_id = s.segment_cable_id
+		WHERE
+			pnc.pnc_project_id IN (%[1]s)
+
+		ORDER BY
+			2, 1;
+	`
+
+	query, args := projectQuery(query, 2, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := make([]*Owner, 0)
+	for rows.Next() {
+		var owner Owner
+
+		err := rows.Scan(
+			&owner.NetworkComponentID,
+			&owner.ProjectID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		owners = append(owners, &owner)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type ProjectModel struct {
+	DB *sql.DB
+}
+
+func (m *ProjectModel) GetAll(ctx context.Context, tenantID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin %w", dbError(err))
+	}
+	defer tx.Rollback()
+
+	err = setSchema(ctx, tx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("set schema %w", dbError(err))
+	}
+
+	projectIDs, err := getProjects(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("get projects %w", dbError(err))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("commit %w", dbError(err))
+	}
+
+	return projectIDs, nil
+}
+
+func getProjects(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	query := `
+		SELECT DISTINCT
+			pnc.pnc_project_id
+		FROM
+			project_network_component pnc
+		ORDER BY
+			pnc.pnc_project_id;
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projectIDs := make([]string, 0)
+	for rows.Next() {
+		var projectID string
+		err := rows.Scan(&projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projectIDs, nil
+}
+
+// checkProjects fails with ErrProjectNotFound unless every project in
+// projectIDs has network components in the tenant schema.
+func checkProjects(ctx context.Context, tx *sql.Tx, projectIDs []string) error {
+	query := `
+		SELECT DISTINCT
+			pnc.pnc_project_id
+		FROM
+			project_network_component pnc
+		WHERE
+			pnc.pnc_project_id IN (%s);
+	`
+
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := make([]string, 0, len(projectIDs))
+	for rows.Next() {
+		var projectID string
+		err := rows.Scan(&projectID)
+		if err != nil {
+			return err
+		}
+
+		found = append(found, projectID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, projectID := range projectIDs {
+		if !slices.Contains(found, projectID) {
+			missing = append(missing, projectID)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrProjectNotFound, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
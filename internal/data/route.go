@@ -0,0 +1,66 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RoutePoint is a vertex of the path a cable segment follows on the map,
+// ordered by Sequence from one end of the segment to the other.
+type RoutePoint struct {
+	SegmentID string  `json:"segment_id" yaml:"segment_id"`
+	Sequence  int     `json:"sequence" yaml:"sequence"`
+	Latitude  float64 `json:"latitude" yaml:"latitude"`
+	Longitude float64 `json:"longitude" yaml:"longitude"`
+}
+
+func getRoutePoints(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*RoutePoint, error) {
+	query := `
+		SELECT DISTINCT
+			sp.segment_point_segment_id,
+			sp.segment_point_sequence,
+			sp.segment_point_latitude,
+			sp.segment_point_longitude
+		FROM
+			segment_point sp
+			LEFT OUTER JOIN segment s ON s.segment_id = sp.segment_point_segment_id
+			LEFT OUTER JOIN cable c ON c.cable_id = s.segment_cable_id
+			LEFT OUTER JOIN network_component nc ON nc.nc_id = c.cable_id
+			LEFT OUTER JOIN project_network_component pnc ON pnc.pnc_network_component_id = nc.nc_id
+		WHERE
+			pnc.pnc_project_id IN (%s)
+		ORDER BY
+			sp.segment_point_segment_id,
+			sp.segment_point_sequence;
+	`
+
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make([]*RoutePoint, 0)
+	for rows.Next() {
+		var point RoutePoint
+
+		err := rows.Scan(
+			&point.SegmentID,
+			&point.Sequence,
+			&point.Latitude,
+			&point.Longitude,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, &point)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
@@ -3,51 +3,19 @@ package data
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
 )
 
 type Sensor struct {
-	DevEUI  string
-	Status  string
-	FiberID string
+	ID      string `json:"id" yaml:"id"`
+	DevEUI  string `json:"dev_eui" yaml:"dev_eui"`
+	Status  string `json:"status" yaml:"status"`
+	FiberID string `json:"fiber_id" yaml:"fiber_id"`
 }
 
-type SensorModel struct {
-	DB *sql.DB
-}
-
-func (m *SensorModel) GetAll(tenantID, projectID string) ([]*Sensor, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	tx, err := m.DB.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("begin %w", err)
-	}
-	defer tx.Rollback()
-
-	err = setSchema(ctx, tx, tenantID)
-	if err != nil {
-		return nil, fmt.Errorf("set schema %w", err)
-	}
-
-	sensors, err := getSensors(ctx, tx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("get sensor %w", err)
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return nil, fmt.Errorf("commit %w", err)
-	}
-
-	return sensors, nil
-}
-
-func getSensors(ctx context.Context, tx *sql.Tx, projectID string) ([]*Sensor, error) {
+func getSensors(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Sensor, error) {
 	query := `
-		SELECT 
+		SELECT DISTINCT
+			s.sensor_network_component_id,
 			s.sensor_deveui,
 			s.sensor_operational_status,
 			p.port_network_component_id
@@ -57,10 +25,11 @@ func getSensors(ctx context.Context, tx *sql.Tx, projectID string) ([]*Sensor, e
 			LEFT OUTER JOIN project_network_component pnc ON pnc_network_component_id = nc.nc_id 
 			LEFT OUTER JOIN port p ON p.port_id = s.sensor_port_id
 		WHERE
-			pnc.pnc_project_id = ?;
+			pnc.pnc_project_id IN (%s);
 	`
 
-	rows, err := tx.QueryContext(ctx, query, projectID)
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +39,7 @@ func getSensors(ctx context.Context, tx *sql.Tx, projectID string) ([]*Sensor, e
 	for rows.Next() {
 		var sensor Sensor
 		err := rows.Scan(
+			&sensor.ID,
 			&sensor.DevEUI,
 			&sensor.Status,
 			&sensor.FiberID,
@@ -0,0 +1,20 @@
+package data
+
+import "context"
+
+// TopologySource provides the network records correlation runs on. Models
+// reads them from the tenant's OSP schema; MemorySource serves fixtures and
+// snapshot files without a database.
+type TopologySource interface {
+	LoadTopology(ctx context.Context, tenantID string, projectIDs ...string) (*Topology, error)
+	Projects(ctx context.Context, tenantID string) ([]string, error)
+	Devices(ctx context.Context, tenantID string) ([]*Device, error)
+}
+
+func (m *Models) Projects(ctx context.Context, tenantID string) ([]string, error) {
+	return m.Project.GetAll(ctx, tenantID)
+}
+
+func (m *Models) Devices(ctx context.Context, tenantID string) ([]*Device, error) {
+	return m.Device.GetAll(ctx, tenantID)
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Span struct {
+	FiberID    string   `json:"fiber_id" yaml:"fiber_id"`
+	SegmentID  string   `json:"segment_id" yaml:"segment_id"`
+	Length     *float64 `json:"length" yaml:"length"`
+	ClosureIDs *string  `json:"closure_ids" yaml:"closure_ids"`
+}
+
+func getSpans(ctx context.Context, tx *sql.Tx, projectIDs []string) ([]*Span, error) {
+	query := `
+		SELECT
+			f.fiber_id,
+			f.fiber_segment_id,
+			s.segment_length,
+			GROUP_CONCAT(DISTINCT p.port_splice_closure_network_component_id)
+		FROM
+			fiber f
+			LEFT OUTER JOIN segment s ON s.segment_id = f.fiber_segment_id
+			LEFT OUTER JOIN cable c ON c.cable_id = s.segment_cable_id
+			LEFT OUTER JOIN network_component nc ON nc.nc_id = c.cable_id
+			LEFT OUTER JOIN project_network_component pnc ON pnc.pnc_network_component_id = nc.nc_id
+			LEFT OUTER JOIN port p ON p.port_network_component_id = f.fiber_id
+		WHERE
+			pnc.pnc_project_id IN (%s)
+		GROUP BY
+			f.fiber_id;
+	`
+
+	query, args := projectQuery(query, 1, projectIDs)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spans := make([]*Span, 0)
+	for rows.Next() {
+		var span Span
+		err := rows.Scan(
+			&span.FiberID,
+			&span.SegmentID,
+			&span.Length,
+			&span.ClosureIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		spans = append(spans, &span)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return spans, nil
+}
@@ -0,0 +1,133 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const topologyTimeout = 15 * time.Second
+
+type Topology struct {
+	Connections []*Connection `json:"connections" yaml:"connections"`
+	Sensors     []*Sensor     `json:"sensors" yaml:"sensors"`
+	ONUs        []*ONU        `json:"onus" yaml:"onus"`
+	Components  []*Component  `json:"components" yaml:"components"`
+	Spans       []*Span       `json:"spans" yaml:"spans"`
+	Locations   []*Location   `json:"locations" yaml:"locations"`
+	Routes      []*RoutePoint `json:"routes" yaml:"routes"`
+	Owners      []*Owner      `json:"owners,omitempty" yaml:"owners,omitempty"`
+	Version     string        `json:"-" yaml:"-"`
+	Timings     []QueryTiming `json:"-" yaml:"-"`
+}
+
+type QueryTiming struct {
+	Query    string
+	Rows     int
+	Duration time.Duration
+}
+
+// LoadTopology reads every table the correlation needs inside one read-only
+// REPEATABLE READ transaction, so all queries see the same snapshot of the
+// tenant schema. The transaction is bound to ctx and aborted when it is done.
+func (m *Models) LoadTopology(ctx context.Context, tenantID string, projectIDs ...string) (*Topology, error) {
+	ctx, cancel := context.WithTimeout(ctx, topologyTimeout)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin %w", dbError(err))
+	}
+	defer tx.Rollback()
+
+	err = setSchema(ctx, tx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("set schema %w", dbError(err))
+	}
+
+	err = checkProjects(ctx, tx, projectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("check projects %w", dbError(err))
+	}
+
+	var topology Topology
+
+	topology.Connections, err = timeQuery(ctx, tx, &topology, "connections", projectIDs, getConnections)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Sensors, err = timeQuery(ctx, tx, &topology, "sensors", projectIDs, getSensors)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.ONUs, err = timeQuery(ctx, tx, &topology, "onus", projectIDs, getONUs)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Components, err = timeQuery(ctx, tx, &topology, "components", projectIDs, getComponents)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Spans, err = timeQuery(ctx, tx, &topology, "spans", projectIDs, getSpans)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Locations, err = timeQuery(ctx, tx, &topology, "locations", projectIDs, getLocations)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Routes, err = timeQuery(ctx, tx, &topology, "routes", projectIDs, getRoutePoints)
+	if err != nil {
+		return nil, err
+	}
+
+	topology.Owners, err = timeQuery(ctx, tx, &topology, "owners", projectIDs, getOwners)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("commit %w", dbError(err))
+	}
+
+	topology.Version, err = topologyVersion(&topology)
+	if err != nil {
+		return nil, err
+	}
+
+	return &topology, nil
+}
+
+func topologyVersion(topology *Topology) (string, error) {
+	js, err := json.Marshal(topology)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(js)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+func timeQuery[T any](ctx context.Context, tx *sql.Tx, topology *Topology, name string, projectIDs []string, get func(context.Context, *sql.Tx, []string) ([]T, error)) ([]T, error) {
+	start := time.Now()
+
+	rows, err := get(ctx, tx, projectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get %s %w", name, dbError(err))
+	}
+
+	topology.Timings = append(topology.Timings, QueryTiming{Query: name, Rows: len(rows), Duration: time.Since(start)})
+
+	return rows, nil
+}
@@ -0,0 +1,252 @@
+package evaluation
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/synthetic"
+)
+
+// Noise corrupts the device reports of a trial the way field data does.
+type Noise struct {
+	// Missing is the probability of a device not reporting at all.
+	Missing float64 `json:"missing"`
+	// Inconsistent is the probability of a sensor reporting the opposite
+	// of what its fiber carries.
+	Inconsistent float64 `json:"inconsistent"`
+	// DyingGasp is the probability of a lit ONU reporting alarmed because
+	// the customer powered it off.
+	DyingGasp float64 `json:"dying_gasp"`
+}
+
+type Config struct {
+	Seed   uint64
+	Trials int
+	Faults []int
+	Noise  Noise
+}
+
+func (cfg Config) Check() error {
+	if cfg.Trials <= 0 {
+		return errors.New("trials must be positive")
+	}
+	if len(cfg.Faults) == 0 {
+		return errors.New("at least one fault count is required")
+	}
+	for _, faults := range cfg.Faults {
+		if faults <= 0 {
+			return fmt.Errorf("invalid fault count %d", faults)
+		}
+	}
+
+	for _, p := range []float64{cfg.Noise.Missing, cfg.Noise.Inconsistent, cfg.Noise.DyingGasp} {
+		if p < 0 || p > 1 {
+			return errors.New("noise probabilities must be in [0, 1]")
+		}
+	}
+
+	return nil
+}
+
+// Evaluate runs cfg.Trials correlations for every fault count, each with a
+// fresh set of random cuts and noisy device reports, and scores the
+// incidents the engine locates against the cut fibers.
+func Evaluate(ctx context.Context, network *synthetic.Network, cfg Config) (*Report, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, ^cfg.Seed))
+	report := &Report{Noise: cfg.Noise, Rows: make([]*Row, 0, len(cfg.Faults))}
+
+	for _, faults := range cfg.Faults {
+		row := &Row{Faults: faults, Distances: make(map[int]int)}
+
+		for range cfg.Trials {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			err := trial(network, faults, cfg.Noise, rng, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		row.finish()
+		report.Rows = append(report.Rows, row)
+	}
+
+	report.finish()
+
+	return report, nil
+}
+
+func trial(network *synthetic.Network, faults int, noise Noise, rng *rand.Rand, row *Row) error {
+	cuts, err := network.RandomCuts(faults)
+	if err != nil {
+		return err
+	}
+
+	fault, err := network.Fault(cuts...)
+	if err != nil {
+		return err
+	}
+
+	return score(network, fault, addNoise(fault.Status, noise, rng), row)
+}
+
+// score correlates the device reports of a fault and adds the incidents the
+// engine locates to row.
+func score(network *synthetic.Network, fault *synthetic.Fault, status snapshot.EquipmentStatus, row *Row) error {
+	bundle := network.Bundle("evaluation", "evaluation", fault)
+	bundle.EquipmentStatus = status
+
+	c, err := bundle.Correlation()
+	if err != nil {
+		return fmt.Errorf("correlate cuts %v: %w", fault.Cuts, err)
+	}
+
+	cut := make(map[string]bool, len(fault.Cuts))
+	for _, id := range fault.Cuts {
+		cut[id] = true
+	}
+	dark := make(map[string]bool, len(fault.Dark))
+	for _, id := range fault.Dark {
+		dark[id] = true
+	}
+
+	row.Trials++
+
+	predicted := make(map[*correlation.Node]bool)
+	for _, incident := range c.Incidents() {
+		node := incident.Node
+		if !dark[node.ID] {
+			row.FalseAlarms++
+			continue
+		}
+
+		predicted[node] = true
+		row.Predictions++
+		if cut[node.ID] {
+			row.TruePositives++
+		}
+	}
+
+	type match struct {
+		cut      string
+		node     *correlation.Node
+		distance int
+	}
+
+	candidates := make([]match, 0)
+	for _, id := range fault.Cuts {
+		row.Cuts++
+
+		node, ok := c.Node(id)
+		if !ok {
+			continue
+		}
+		for incident, distance := range hops(node, predicted) {
+			candidates = append(candidates, match{cut: id, node: incident, distance: distance})
+		}
+	}
+
+	// Every cut claims the nearest incident no closer cut has claimed, so
+	// one incident is never credited to two cuts.
+	slices.SortFunc(candidates, func(a, b match) int {
+		return cmp.Or(
+			cmp.Compare(a.distance, b.distance),
+			cmp.Compare(a.cut, b.cut),
+			cmp.Compare(a.node.ID, b.node.ID),
+		)
+	})
+
+	matched := make(map[string]bool, len(fault.Cuts))
+	claimed := make(map[*correlation.Node]bool, len(predicted))
+	for _, m := range candidates {
+		if matched[m.cut] || claimed[m.node] {
+			continue
+		}
+		matched[m.cut] = true
+		claimed[m.node] = true
+
+		if m.distance == 0 {
+			row.Found++
+		}
+		row.Distances[m.distance]++
+	}
+	row.Missed += len(fault.Cuts) - len(matched)
+
+	return nil
+}
+
+func addNoise(status snapshot.EquipmentStatus, noise Noise, rng *rand.Rand) snapshot.EquipmentStatus {
+	var noisy snapshot.EquipmentStatus
+
+	report := func(list *[]string, id string) {
+		if rng.Float64() >= noise.Missing {
+			*list = append(*list, id)
+		}
+	}
+
+	for _, id := range status.ActiveSensors {
+		if rng.Float64() < noise.Inconsistent {
+			report(&noisy.AlarmedSensors, id)
+		} else {
+			report(&noisy.ActiveSensors, id)
+		}
+	}
+	for _, id := range status.AlarmedSensors {
+		if rng.Float64() < noise.Inconsistent {
+			report(&noisy.ActiveSensors, id)
+		} else {
+			report(&noisy.AlarmedSensors, id)
+		}
+	}
+	for _, id := range status.ActiveONUs {
+		if rng.Float64() < noise.DyingGasp {
+			report(&noisy.AlarmedONUs, id)
+		} else {
+			report(&noisy.ActiveONUs, id)
+		}
+	}
+	for _, id := range status.AlarmedONUs {
+		report(&noisy.AlarmedONUs, id)
+	}
+
+	return noisy
+}
+
+// hops is the distance, in either direction along the network, from node to
+// every predicted incident it can reach.
+func hops(node *correlation.Node, predicted map[*correlation.Node]bool) map[*correlation.Node]int {
+	distance := map[*correlation.Node]int{node: 0}
+	queue := []*correlation.Node{node}
+	found := make(map[*correlation.Node]int)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if predicted[current] {
+			found[current] = distance[current]
+		}
+
+		for _, next := range slices.Concat(current.Parents, current.Children) {
+			if _, ok := distance[next]; ok {
+				continue
+			}
+			distance[next] = distance[current] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return found
+}
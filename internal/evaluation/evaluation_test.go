@@ -0,0 +1,175 @@
+package evaluation
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+	"github.com/matheusrb95/fibergraph/internal/synthetic"
+)
+
+// testNetwork builds two feeders of a DIO, each ending in a splitter with
+// two drop fibers of one ONU:
+//
+//	CO-1 - DIO-1 - F-1 - S-1 - F-2 (ONU-A), F-3 (ONU-B)
+//	             - F-4 - S-2 - F-5 (ONU-C), F-6 (ONU-D)
+func testNetwork() *synthetic.Network {
+	topology := &data.Topology{}
+
+	connections := []struct{ id, kind, parent string }{
+		{"CO-1", "CO", ""},
+		{"DIO-1", "DIO", "CO-1"},
+		{"F-1", "Fiber", "DIO-1"},
+		{"S-1", "Splitter", "F-1"},
+		{"F-2", "Fiber", "S-1"},
+		{"F-3", "Fiber", "S-1"},
+		{"F-4", "Fiber", "DIO-1"},
+		{"S-2", "Splitter", "F-4"},
+		{"F-5", "Fiber", "S-2"},
+		{"F-6", "Fiber", "S-2"},
+	}
+	for _, c := range connections {
+		var parents []string
+		if c.parent != "" {
+			parents = []string{c.parent}
+		}
+		topology.Connections = append(topology.Connections, &data.Connection{
+			ID:        c.id,
+			Name:      c.id,
+			ParentIDs: data.JoinIDs(parents),
+			Type:      c.kind,
+		})
+	}
+
+	for fiber, serial := range map[string]string{"F-2": "ONU-A", "F-3": "ONU-B", "F-5": "ONU-C", "F-6": "ONU-D"} {
+		topology.ONUs = append(topology.ONUs, &data.ONU{ID: serial, SerialNumber: serial, FiberID: fiber})
+	}
+
+	return synthetic.FromTopology(topology, 1)
+}
+
+// dyingGasp moves lit ONUs to the alarmed list, as a customer powering
+// them off would.
+func dyingGasp(status snapshot.EquipmentStatus, serials ...string) snapshot.EquipmentStatus {
+	status.ActiveONUs = slices.DeleteFunc(slices.Clone(status.ActiveONUs), func(serial string) bool {
+		return slices.Contains(serials, serial)
+	})
+	status.AlarmedONUs = append(slices.Clone(status.AlarmedONUs), serials...)
+
+	return status
+}
+
+// missing drops devices from every report list.
+func missing(status snapshot.EquipmentStatus, ids ...string) snapshot.EquipmentStatus {
+	drop := func(list []string) []string {
+		return slices.DeleteFunc(slices.Clone(list), func(id string) bool {
+			return slices.Contains(ids, id)
+		})
+	}
+
+	status.ActiveONUs = drop(status.ActiveONUs)
+	status.AlarmedONUs = drop(status.AlarmedONUs)
+
+	return status
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		cuts  []string
+		noise func(snapshot.EquipmentStatus) snapshot.EquipmentStatus
+		want  Row
+	}{
+		{
+			name: "drop cut",
+			cuts: []string{"F-2"},
+			want: Row{
+				Trials: 1, Cuts: 1, Predictions: 1, TruePositives: 1, Found: 1,
+				Precision: 1, Recall: 1,
+				Distances: map[int]int{0: 1},
+			},
+		},
+		{
+			name: "two drop cuts",
+			cuts: []string{"F-2", "F-5"},
+			want: Row{
+				Trials: 1, Cuts: 2, Predictions: 2, TruePositives: 2, Found: 2,
+				Precision: 1, Recall: 1,
+				Distances: map[int]int{0: 2},
+			},
+		},
+		{
+			name: "feeder cut located at its drops",
+			cuts: []string{"F-1"},
+			want: Row{
+				Trials: 1, Cuts: 1, Predictions: 2,
+				MeanDistance: 2, MaxDistance: 2,
+				Distances: map[int]int{2: 1},
+			},
+		},
+		{
+			name: "dying gasp is a false alarm",
+			cuts: []string{"F-2"},
+			noise: func(status snapshot.EquipmentStatus) snapshot.EquipmentStatus {
+				return dyingGasp(status, "ONU-C")
+			},
+			want: Row{
+				Trials: 1, Cuts: 1, Predictions: 1, TruePositives: 1, FalseAlarms: 1, Found: 1,
+				Precision: 0.5, Recall: 1,
+				Distances: map[int]int{0: 1},
+			},
+		},
+		{
+			name: "incident claimed by another cut",
+			cuts: []string{"F-2", "F-6"},
+			noise: func(status snapshot.EquipmentStatus) snapshot.EquipmentStatus {
+				return missing(status, "ONU-D")
+			},
+			want: Row{
+				Trials: 1, Cuts: 2, Predictions: 1, TruePositives: 1, Found: 1, Missed: 1,
+				Precision: 1, Recall: 0.5,
+				Distances: map[int]int{0: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := testNetwork()
+			fault, err := network.Fault(tt.cuts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			status := fault.Status
+			if tt.noise != nil {
+				status = tt.noise(status)
+			}
+
+			row := &Row{Distances: make(map[int]int)}
+			err = score(network, fault, status, row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			row.finish()
+
+			got := *row
+			if got.Trials != tt.want.Trials || got.Cuts != tt.want.Cuts || got.Predictions != tt.want.Predictions ||
+				got.TruePositives != tt.want.TruePositives || got.FalseAlarms != tt.want.FalseAlarms ||
+				got.Found != tt.want.Found || got.Missed != tt.want.Missed {
+				t.Errorf("got counts %+v, want %+v", got, tt.want)
+			}
+			if got.Precision != tt.want.Precision || got.Recall != tt.want.Recall {
+				t.Errorf("got precision %v and recall %v, want %v and %v", got.Precision, got.Recall, tt.want.Precision, tt.want.Recall)
+			}
+			if got.MeanDistance != tt.want.MeanDistance || got.MaxDistance != tt.want.MaxDistance {
+				t.Errorf("got mean distance %v and max %d, want %v and %d", got.MeanDistance, got.MaxDistance, tt.want.MeanDistance, tt.want.MaxDistance)
+			}
+			if !maps.Equal(got.Distances, tt.want.Distances) {
+				t.Errorf("got distances %v, want %v", got.Distances, tt.want.Distances)
+			}
+		})
+	}
+}
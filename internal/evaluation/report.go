@@ -0,0 +1,92 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Row scores every trial with the same number of injected faults.
+type Row struct {
+	Faults        int         `json:"faults"`
+	Trials        int         `json:"trials"`
+	Cuts          int         `json:"cuts"`
+	Predictions   int         `json:"predictions"`
+	TruePositives int         `json:"true_positives"`
+	FalseAlarms   int         `json:"false_alarms"`
+	Found         int         `json:"found"`
+	Missed        int         `json:"missed"`
+	Precision     float64     `json:"precision"`
+	Recall        float64     `json:"recall"`
+	MeanDistance  float64     `json:"mean_distance"`
+	MaxDistance   int         `json:"max_distance"`
+	Distances     map[int]int `json:"distances"`
+}
+
+type Report struct {
+	Noise Noise  `json:"noise"`
+	Rows  []*Row `json:"rows"`
+	Total *Row   `json:"total"`
+}
+
+func (row *Row) finish() {
+	if incidents := row.Predictions + row.FalseAlarms; incidents > 0 {
+		row.Precision = float64(row.TruePositives) / float64(incidents)
+	}
+	if row.Cuts > 0 {
+		row.Recall = float64(row.Found) / float64(row.Cuts)
+	}
+
+	located, sum := 0, 0
+	for distance, count := range row.Distances {
+		located += count
+		sum += distance * count
+		row.MaxDistance = max(row.MaxDistance, distance)
+	}
+	if located > 0 {
+		row.MeanDistance = float64(sum) / float64(located)
+	}
+}
+
+func (r *Report) finish() {
+	total := &Row{Distances: make(map[int]int)}
+	for _, row := range r.Rows {
+		total.Trials += row.Trials
+		total.Cuts += row.Cuts
+		total.Predictions += row.Predictions
+		total.TruePositives += row.TruePositives
+		total.FalseAlarms += row.FalseAlarms
+		total.Found += row.Found
+		total.Missed += row.Missed
+		for distance, count := range row.Distances {
+			total.Distances[distance] += count
+		}
+	}
+
+	total.finish()
+	r.Total = total
+}
+
+func (r *Report) Write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "noise: missing %.2f, inconsistent %.2f, dying gasp %.2f\n\n", r.Noise.Missing, r.Noise.Inconsistent, r.Noise.DyingGasp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%-8s %7s %6s %9s %7s %7s %12s %9s %9s\n", "faults", "trials", "cuts", "precision", "recall", "missed", "false alarms", "mean hops", "max hops")
+	for _, row := range append(slices.Clone(r.Rows), r.Total) {
+		faults := fmt.Sprint(row.Faults)
+		if row == r.Total {
+			faults = "total"
+		}
+		fmt.Fprintf(w, "%-8s %7d %6d %9.3f %7.3f %7d %12d %9.2f %9d\n", faults, row.Trials, row.Cuts, row.Precision, row.Recall, row.Missed, row.FalseAlarms, row.MeanDistance, row.MaxDistance)
+	}
+
+	fmt.Fprintf(w, "\nlocalization distance (hops: cuts)\n")
+	for _, distance := range slices.Sorted(maps.Keys(r.Total.Distances)) {
+		fmt.Fprintf(w, "  %3d: %d\n", distance, r.Total.Distances[distance])
+	}
+
+	return nil
+}
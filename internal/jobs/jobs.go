@@ -0,0 +1,304 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrQueueFull   = errors.New("job queue is full")
+	ErrQueueClosed = errors.New("job queue is shut down")
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// errShutdown cancels the jobs still waiting in the queue when it shuts down.
+var errShutdown = fmt.Errorf("%w: %w", ErrQueueClosed, context.Canceled)
+
+const retention = time.Hour
+
+type Status string
+
+const (
+	Queued    Status = "QUEUED"
+	Running   Status = "RUNNING"
+	Succeeded Status = "SUCCEEDED"
+	Failed    Status = "FAILED"
+	Canceled  Status = "CANCELED"
+)
+
+// RunFunc computes the result of a job. The returned commit, if any, holds
+// the job's side effects and is called only once the job has succeeded and
+// can no longer be canceled. A result returned along with an error is kept
+// on the failed job to describe the failure.
+type RunFunc func(ctx context.Context) (result any, commit func(), err error)
+
+type Job struct {
+	ID          string     `json:"id"`
+	Status      Status     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CallbackURL string     `json:"callback_url,omitempty"`
+	Result      any        `json:"result,omitempty"`
+	Error       string     `json:"error,omitempty"`
+
+	run    RunFunc
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (j *Job) finished() bool {
+	return j.Status == Succeeded || j.Status == Failed || j.Status == Canceled
+}
+
+type Queue struct {
+	logger *slog.Logger
+	client *http.Client
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+
+	pending chan *Job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewQueue(logger *slog.Logger, size, workers int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &Queue{
+		logger:  logger,
+		client:  callbackClient(),
+		jobs:    make(map[string]*Job),
+		pending: make(chan *Job, size),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	for range workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *Queue) Submit(run RunFunc, callbackURL string) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+	job := &Job{
+		ID:          id,
+		Status:      Queued,
+		CreatedAt:   time.Now(),
+		CallbackURL: callbackURL,
+		run:         run,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.ctx.Err() != nil {
+		cancel()
+		return Job{}, ErrQueueClosed
+	}
+
+	q.prune()
+
+	select {
+	case q.pending <- job:
+	default:
+		cancel()
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[id] = job
+
+	return *job, nil
+}
+
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	return *job, nil
+}
+
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNotFound
+	}
+
+	if job.finished() {
+		return *job, ErrJobFinished
+	}
+
+	job.cancel()
+	if job.Status == Queued {
+		q.finish(job, nil, context.Canceled)
+	}
+
+	return *job, nil
+}
+
+// Shutdown cancels the running jobs and waits for them to finish. Jobs that
+// never started are canceled and their callbacks sent.
+func (q *Queue) Shutdown() {
+	q.cancel()
+	q.wg.Wait()
+
+	// Submit enqueues under the lock, so once it is held no job can be
+	// added behind the ones drained here.
+	q.mu.Lock()
+	var pending []*Job
+	for len(q.pending) > 0 {
+		pending = append(pending, <-q.pending)
+	}
+	q.mu.Unlock()
+
+	for _, job := range pending {
+		q.drop(job)
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case job := <-q.pending:
+			if q.ctx.Err() != nil {
+				q.drop(job)
+				return
+			}
+			q.execute(job)
+		}
+	}
+}
+
+func (q *Queue) execute(job *Job) {
+	q.mu.Lock()
+	if job.finished() {
+		q.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	job.Status = Running
+	job.StartedAt = &now
+	q.mu.Unlock()
+
+	result, commit, err := job.run(job.ctx)
+
+	q.mu.Lock()
+	if err == nil {
+		err = job.ctx.Err()
+	}
+	q.finish(job, result, err)
+	snapshot := *job
+	q.mu.Unlock()
+
+	if snapshot.Status == Succeeded && commit != nil {
+		commit()
+	}
+
+	if snapshot.CallbackURL != "" {
+		q.notify(snapshot)
+	}
+}
+
+// drop cancels a job the queue shut down before it started.
+func (q *Queue) drop(job *Job) {
+	q.mu.Lock()
+	if job.finished() {
+		q.mu.Unlock()
+		return
+	}
+	q.finish(job, nil, errShutdown)
+	snapshot := *job
+	q.mu.Unlock()
+
+	if snapshot.CallbackURL != "" {
+		q.notify(snapshot)
+	}
+}
+
+func (q *Queue) finish(job *Job, result any, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.cancel()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		job.Status = Canceled
+		job.Error = err.Error()
+	case err != nil:
+		job.Status = Failed
+		job.Error = err.Error()
+		job.Result = result
+	default:
+		job.Status = Succeeded
+		job.Result = result
+	}
+}
+
+func (q *Queue) notify(job Job) {
+	body, err := json.Marshal(map[string]any{"job": job})
+	if err != nil {
+		q.logger.Warn("error marshaling job callback", "job_id", job.ID, "err", err.Error())
+		return
+	}
+
+	resp, err := q.client.Post(job.CallbackURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		q.logger.Warn("error sending job callback", "job_id", job.ID, "err", err.Error())
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		q.logger.Warn("job callback rejected", "job_id", job.ID, "status", resp.StatusCode)
+	}
+}
+
+func (q *Queue) prune() {
+	for id, job := range q.jobs {
+		if job.finished() && time.Since(*job.FinishedAt) > retention {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate job id. %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
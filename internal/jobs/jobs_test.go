@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestQueueShutdown(t *testing.T) {
+	var mu sync.Mutex
+	callbacks := make(map[string]Status)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Job Job `json:"job"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode callback: %v", err)
+			return
+		}
+		mu.Lock()
+		callbacks[body.Job.ID] = body.Job.Status
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	q := NewQueue(slog.New(slog.NewTextHandler(io.Discard, nil)), 10, 1)
+	q.client = srv.Client()
+
+	started := make(chan struct{})
+	running, err := q.Submit(func(ctx context.Context) (any, func(), error) {
+		close(started)
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	noop := func(ctx context.Context) (any, func(), error) {
+		t.Error("queued job ran after shutdown")
+		return nil, nil, nil
+	}
+	queued, err := q.Submit(noop, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled, err := q.Submit(noop, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Cancel(canceled.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	q.Shutdown()
+
+	tests := []struct {
+		name         string
+		id           string
+		wantCallback bool
+	}{
+		{name: "running", id: running.ID, wantCallback: true},
+		{name: "queued", id: queued.ID, wantCallback: true},
+		{name: "canceled before shutdown", id: canceled.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := q.Get(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Status != Canceled || job.FinishedAt == nil {
+				t.Errorf("got status %s, finished %v, want %s", job.Status, job.FinishedAt, Canceled)
+			}
+
+			mu.Lock()
+			status, ok := callbacks[tt.id]
+			mu.Unlock()
+			if ok != tt.wantCallback {
+				t.Fatalf("got callback %v, want %v", ok, tt.wantCallback)
+			}
+			if ok && status != Canceled {
+				t.Errorf("got callback status %s, want %s", status, Canceled)
+			}
+		})
+	}
+
+	if _, err := q.Submit(noop, ""); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("got error %v, want %v", err, ErrQueueClosed)
+	}
+}
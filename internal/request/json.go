@@ -1,6 +1,8 @@
 package request
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +11,28 @@ import (
 	"strings"
 )
 
+const DefaultMaxBytes = 1_048_576
+
+// DecompressionRatio bounds how much larger than the size limit a
+// compressed body may grow once decompressed.
+const DecompressionRatio = 4
+
+type contextKey string
+
+const maxBytesContextKey = contextKey("maxBytes")
+
+var errInvalidGzip = errors.New("body contains invalid gzip data")
+
+// MaxBytes overrides the body size limit enforced by the decoders for the
+// requests served by next. The limit applies to the body as sent; a gzip
+// body may decompress to at most DecompressionRatio times that.
+func MaxBytes(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), maxBytesContextKey, maxBytes)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	return decodeJSON(w, r, dst, false)
 }
@@ -17,57 +41,174 @@ func DecodeJSONStrict(w http.ResponseWriter, r *http.Request, dst any) error {
 	return decodeJSON(w, r, dst, true)
 }
 
+// DecodeNDJSON decodes a stream of newline-delimited JSON values, calling fn
+// for each one as soon as it is read.
+func DecodeNDJSON[T any](w http.ResponseWriter, r *http.Request, disallowUnknownFields bool, fn func(T) error) error {
+	maxBytes := maxBytesFromContext(r.Context())
+
+	body, err := requestBody(w, r, maxBytes)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+
+	if disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	line := 0
+	for {
+		var value T
+		err := dec.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, decodeError(err, maxBytes))
+		}
+
+		err = fn(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	if line == 0 {
+		return errors.New("body must not be empty")
+	}
+
+	return nil
+}
+
 func decodeJSON(w http.ResponseWriter, r *http.Request, dst any, disallowUnknownFields bool) error {
-	maxBytes := 1_048_576
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	maxBytes := maxBytesFromContext(r.Context())
+
+	body, err := requestBody(w, r, maxBytes)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
 
-	dec := json.NewDecoder(r.Body)
+	dec := json.NewDecoder(body)
 
 	if disallowUnknownFields {
 		dec.DisallowUnknownFields()
 	}
 
-	err := dec.Decode(dst)
+	err = dec.Decode(dst)
 	if err != nil {
-		var syntaxError *json.SyntaxError
-		var unmarshalTypeError *json.UnmarshalTypeError
-		var invalidUnmarshalError *json.InvalidUnmarshalError
+		return decodeError(err, maxBytes)
+	}
 
-		switch {
-		case errors.As(err, &syntaxError):
-			return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+	err = dec.Decode(&struct{}{})
+	switch {
+	case errors.Is(err, io.EOF):
+	case isBodyError(err):
+		return decodeError(err, maxBytes)
+	default:
+		return errors.New("body must only contain a single JSON value")
+	}
 
-		case errors.Is(err, io.ErrUnexpectedEOF):
-			return errors.New("body contains badly-formed JSON")
+	return nil
+}
 
-		case errors.As(err, &unmarshalTypeError):
-			if unmarshalTypeError.Field != "" {
-				return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
+func requestBody(w http.ResponseWriter, r *http.Request, maxBytes int64) (io.ReadCloser, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	switch strings.ToLower(r.Header.Get("Content-Encoding")) {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			if err.Error() == "http: request body too large" {
+				return nil, fmt.Errorf("body must not be larger than %d bytes", maxBytes)
 			}
-			return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
+			return nil, errInvalidGzip
+		}
+		return http.MaxBytesReader(w, gzipReader{gz}, DecompressionRatio*maxBytes), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", r.Header.Get("Content-Encoding"))
+	}
+}
 
-		case errors.Is(err, io.EOF):
-			return errors.New("body must not be empty")
+// gzipReader reports a stream cut short as invalid gzip data, so that it is
+// not mistaken for a JSON value cut short.
+type gzipReader struct {
+	*gzip.Reader
+}
 
-		case strings.HasPrefix(err.Error(), "json: unknown field "):
-			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
-			return fmt.Errorf("body contains unknown key %s", fieldName)
+func (r gzipReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = errInvalidGzip
+	}
 
-		case err.Error() == "http: request body too large":
-			return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+	return n, err
+}
+
+// isBodyError reports whether err comes from reading the body rather than
+// from decoding the JSON in it.
+func isBodyError(err error) bool {
+	var maxBytesError *http.MaxBytesError
 
-		case errors.As(err, &invalidUnmarshalError):
-			panic(err)
+	return errors.As(err, &maxBytesError) ||
+		errors.Is(err, errInvalidGzip) ||
+		errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, gzip.ErrHeader)
+}
 
-		default:
-			return err
+func decodeError(err error, maxBytes int64) error {
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var invalidUnmarshalError *json.InvalidUnmarshalError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("body contains badly-formed JSON (at character %d)", syntaxError.Offset)
+
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New("body contains badly-formed JSON")
+
+	case errors.As(err, &unmarshalTypeError):
+		if unmarshalTypeError.Field != "" {
+			return fmt.Errorf("body contains incorrect JSON type for field %q", unmarshalTypeError.Field)
 		}
+		return fmt.Errorf("body contains incorrect JSON type (at character %d)", unmarshalTypeError.Offset)
+
+	case errors.Is(err, io.EOF):
+		return errors.New("body must not be empty")
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("body contains unknown key %s", fieldName)
+
+	case errors.As(err, &maxBytesError) && maxBytesError.Limit > maxBytes:
+		return fmt.Errorf("decompressed body must not be larger than %d bytes", maxBytesError.Limit)
+
+	case errors.As(err, &maxBytesError):
+		return fmt.Errorf("body must not be larger than %d bytes", maxBytes)
+
+	case errors.Is(err, errInvalidGzip), errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader):
+		return errInvalidGzip
+
+	case errors.As(err, &invalidUnmarshalError):
+		panic(err)
+
+	default:
+		return err
 	}
+}
 
-	err = dec.Decode(&struct{}{})
-	if !errors.Is(err, io.EOF) {
-		return errors.New("body must only contain a single JSON value")
+func maxBytesFromContext(ctx context.Context) int64 {
+	maxBytes, ok := ctx.Value(maxBytesContextKey).(int64)
+	if !ok || maxBytes <= 0 {
+		return DefaultMaxBytes
 	}
 
-	return nil
+	return maxBytes
 }
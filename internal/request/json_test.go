@@ -0,0 +1,128 @@
+package request
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipBody(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecodeJSONGzipLimit(t *testing.T) {
+	const maxBytes = 1024
+
+	// A repeated character compresses to far below maxBytes whatever its
+	// decompressed size.
+	padded := func(size int) string {
+		return `{"name":"` + strings.Repeat("x", size-len(`{"name":""}`)) + `"}`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "within the limit", body: padded(maxBytes / 2)},
+		{name: "larger than the limit within the ratio", body: padded(DecompressionRatio*maxBytes - 1)},
+		{name: "larger than the ratio", body: padded(DecompressionRatio*maxBytes + 1), wantErr: "decompressed body must not be larger than 4096 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := gzipBody(t, tt.body)
+			if len(body) > maxBytes {
+				t.Fatalf("compressed body is %d bytes, want at most %d", len(body), maxBytes)
+			}
+
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			r.Header.Set("Content-Encoding", "gzip")
+			w := httptest.NewRecorder()
+
+			var dst struct {
+				Name string `json:"name"`
+			}
+			var err error
+			MaxBytes(maxBytes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				err = DecodeJSON(w, r, &dst)
+			})).ServeHTTP(w, r)
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("got error %q, want none", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeGzipErrors(t *testing.T) {
+	const maxBytes = 4096
+
+	value := `{"name":"` + strings.Repeat("ab", 1000) + `"}`
+	full := gzipBody(t, value)
+	corrupt := bytes.Clone(full)
+	corrupt[len(corrupt)-5] ^= 0xff
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	random := make([]byte, 2*maxBytes)
+	for i := range random {
+		random[i] = 'a' + byte(rng.IntN(26))
+	}
+
+	tests := []struct {
+		name    string
+		body    []byte
+		ndjson  bool
+		wantErr string
+	}{
+		{name: "bomb in a value", body: gzipBody(t, `{"name":"`+strings.Repeat("x", 1<<20)+`"}`), wantErr: "decompressed body must not be larger than 16384 bytes"},
+		{name: "bomb after a value", body: gzipBody(t, `{"name":"x"}`+strings.Repeat(" ", 1<<20)), wantErr: "decompressed body must not be larger than 16384 bytes"},
+		{name: "bomb in ndjson", body: gzipBody(t, `{"name":"x"}`+"\n"+`{"name":"`+strings.Repeat("x", 1<<20)+`"}`), ndjson: true, wantErr: "line 2: decompressed body must not be larger than 16384 bytes"},
+		{name: "compressed body too large", body: gzipBody(t, `{"name":"`+string(random)+`"}`), wantErr: "body must not be larger than 4096 bytes"},
+		{name: "truncated header", body: full[:5], wantErr: "body contains invalid gzip data"},
+		{name: "truncated in a value", body: full[:len(full)/2], wantErr: "body contains invalid gzip data"},
+		{name: "truncated trailer", body: full[:len(full)-3], wantErr: "body contains invalid gzip data"},
+		{name: "bad checksum", body: corrupt, wantErr: "body contains invalid gzip data"},
+		{name: "not gzip", body: []byte(value), wantErr: "body contains invalid gzip data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(tt.body))
+			r.Header.Set("Content-Encoding", "gzip")
+			w := httptest.NewRecorder()
+
+			var err error
+			MaxBytes(maxBytes, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.ndjson {
+					err = DecodeNDJSON(w, r, false, func(struct{ Name string }) error { return nil })
+					return
+				}
+				var dst struct{ Name string }
+				err = DecodeJSON(w, r, &dst)
+			})).ServeHTTP(w, r)
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
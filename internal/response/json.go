@@ -18,11 +18,26 @@ func JSONWithHeaders(w http.ResponseWriter, status int, data Envelope, headers h
 		return err
 	}
 
+	return write(w, status, js, headers)
+}
+
+func CompactJSON(w http.ResponseWriter, status int, data Envelope) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return write(w, status, js, nil)
+}
+
+func write(w http.ResponseWriter, status int, js []byte, headers http.Header) error {
 	js = append(js, '\n')
 
 	maps.Copy(w.Header(), headers)
 
-	w.Header().Set("Content-Type", "application/json")
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
 	w.WriteHeader(status)
 	w.Write(js)
 
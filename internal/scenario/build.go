@@ -0,0 +1,228 @@
+package scenario
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+type builder struct {
+	topology    *data.Topology
+	kinds       map[string]string
+	connections map[string]*data.Connection
+	parents     map[string][]string
+	children    map[string][]string
+	closures    map[string]*data.Component
+	members     map[string][]string
+	ratios      map[string]int
+}
+
+// Topology converts the network of the scenario into the rows the OSP
+// database would return for it.
+func (s *Scenario) Topology() (*data.Topology, error) {
+	b := &builder{
+		topology:    &data.Topology{},
+		kinds:       make(map[string]string),
+		connections: make(map[string]*data.Connection),
+		parents:     make(map[string][]string),
+		children:    make(map[string][]string),
+		closures:    make(map[string]*data.Component),
+		members:     make(map[string][]string),
+		ratios:      make(map[string]int),
+	}
+
+	for _, node := range s.Network {
+		if err := b.add(node, "", ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.link(); err != nil {
+		return nil, err
+	}
+
+	return b.topology, nil
+}
+
+// add walks the tree. parent is the nearest connection above node and
+// closure the closure node belongs to, if no other fiber lies in between.
+func (b *builder) add(node *Node, parent, closure string) error {
+	kind, id, err := node.kind()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := b.kinds[id]; ok {
+		return fmt.Errorf("duplicate node %s", id)
+	}
+	b.kinds[id] = kind
+
+	switch kind {
+	case "ceo", "cto":
+		component := &data.Component{ID: id, Type: strings.ToUpper(kind)}
+		b.closures[id] = component
+		b.topology.Components = append(b.topology.Components, component)
+
+		for _, child := range node.Children {
+			if err := b.add(child, parent, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "sensor", "onu":
+		if parent == "" || b.kinds[parent] != "fiber" {
+			return fmt.Errorf("%s %s must be attached to a fiber", kind, id)
+		}
+		if len(node.Children) > 0 {
+			return fmt.Errorf("%s %s cannot have children", kind, id)
+		}
+
+		if kind == "sensor" {
+			b.topology.Sensors = append(b.topology.Sensors, &data.Sensor{ID: id, DevEUI: id, Status: node.Status, FiberID: parent})
+		} else {
+			b.topology.ONUs = append(b.topology.ONUs, &data.ONU{ID: id, SerialNumber: id, Status: node.Status, FiberID: parent})
+		}
+
+		return nil
+	}
+
+	name := node.Name
+	if name == "" {
+		name = id
+	}
+
+	connection := &data.Connection{ID: id, Name: name, Type: connectionType(kind)}
+	b.connections[id] = connection
+	b.topology.Connections = append(b.topology.Connections, connection)
+
+	if kind == "splitter" {
+		outputs, err := node.outputs()
+		if err != nil {
+			return err
+		}
+		b.ratios[id] = outputs
+	}
+
+	if parent != "" {
+		b.parents[id] = append(b.parents[id], parent)
+	}
+	b.parents[id] = append(b.parents[id], node.Parents...)
+
+	if closure != "" && kind == "fiber" {
+		b.members[closure] = append(b.members[closure], id)
+	}
+	if kind == "fiber" {
+		closure = ""
+	}
+
+	for _, child := range node.Children {
+		if err := b.add(child, id, closure); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// link resolves parent references once every node is known and fills in
+// the comma-joined ID columns.
+func (b *builder) link() error {
+	for _, connection := range b.topology.Connections {
+		for _, parentID := range b.parents[connection.ID] {
+			if _, ok := b.connections[parentID]; !ok {
+				return fmt.Errorf("%s has unknown parent %s", connection.ID, parentID)
+			}
+			if !slices.Contains(b.children[parentID], connection.ID) {
+				b.children[parentID] = append(b.children[parentID], connection.ID)
+			}
+		}
+	}
+
+	for _, connection := range b.topology.Connections {
+		connection.ParentIDs = data.JoinIDs(b.parents[connection.ID])
+		connection.ChildrenIDs = data.JoinIDs(b.children[connection.ID])
+
+		if outputs := b.ratios[connection.ID]; outputs > 0 && len(b.children[connection.ID]) > outputs {
+			return fmt.Errorf("splitter %s has %d outputs in use, more than its ratio 1:%d", connection.ID, len(b.children[connection.ID]), outputs)
+		}
+	}
+
+	for _, component := range b.topology.Components {
+		component.FiberIDs = data.JoinIDs(b.members[component.ID])
+	}
+
+	return nil
+}
+
+// connectionType maps a node kind to the connection type of the OSP database.
+func connectionType(kind string) string {
+	for _, t := range data.ConnectionTypes {
+		if strings.EqualFold(t, kind) {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// Bundle packs the scenario as a snapshot so it can be fed to every tool
+// that reads snapshots.
+func (s *Scenario) Bundle() (*snapshot.Bundle, error) {
+	topology, err := s.Topology()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make(map[string]string)
+	for _, sensor := range topology.Sensors {
+		devices[sensor.DevEUI] = "sensor"
+	}
+	for _, onu := range topology.ONUs {
+		devices[onu.SerialNumber] = "onu"
+	}
+
+	var status snapshot.EquipmentStatus
+	observed := []struct {
+		name    string
+		ids     []string
+		sensors *[]string
+		onus    *[]string
+	}{
+		{"active", s.Observed.Active, &status.ActiveSensors, &status.ActiveONUs},
+		{"alarmed", s.Observed.Alarmed, &status.AlarmedSensors, &status.AlarmedONUs},
+		{"inactive", s.Observed.Inactive, &status.InactiveSensors, nil},
+	}
+	for _, o := range observed {
+		for _, id := range o.ids {
+			switch {
+			case devices[id] == "sensor":
+				*o.sensors = append(*o.sensors, id)
+			case devices[id] == "onu" && o.onus != nil:
+				*o.onus = append(*o.onus, id)
+			case devices[id] == "onu":
+				return nil, fmt.Errorf("onu %s cannot be reported %s", id, o.name)
+			default:
+				return nil, fmt.Errorf("observed %s device %s is not in the network", o.name, id)
+			}
+		}
+	}
+
+	for id, name := range s.Expect {
+		if _, ok := correlation.ParseStatus(name); !ok {
+			return nil, fmt.Errorf("expected status of %s is unknown: %q", id, name)
+		}
+	}
+
+	return &snapshot.Bundle{
+		Version:         snapshot.FormatVersion,
+		TenantID:        "scenario",
+		ProjectIDs:      []string{s.Name},
+		Topology:        topology,
+		EquipmentStatus: status,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+)
+
+const missing = "MISSING"
+
+type Mismatch struct {
+	NodeID   string `json:"node_id"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: expected %s, got %s", m.NodeID, m.Expected, m.Actual)
+}
+
+type Result struct {
+	Scenario   string      `json:"scenario"`
+	Checked    int         `json:"checked"`
+	Mismatches []*Mismatch `json:"mismatches"`
+	Error      string      `json:"error,omitempty"`
+}
+
+func (r *Result) Passed() bool {
+	return r.Error == "" && len(r.Mismatches) == 0
+}
+
+// Run correlates the scenario and compares every expected node status with
+// the one the engine produced. An invalid scenario is reported as an error
+// in the result rather than as a mismatch, unless the scenario expects it.
+func (s *Scenario) Run() *Result {
+	result := &Result{Scenario: s.Name, Mismatches: make([]*Mismatch, 0)}
+
+	c, err := s.correlate()
+	if s.Error != "" {
+		result.Checked++
+		switch {
+		case err == nil:
+			result.Error = fmt.Sprintf("expected error %q, got none", s.Error)
+		case !strings.Contains(err.Error(), s.Error):
+			result.Error = fmt.Sprintf("expected error %q, got %q", s.Error, err)
+		}
+		return result
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(s.Expect)) {
+		expected, _ := correlation.ParseStatus(s.Expect[id])
+		result.Checked++
+
+		node, ok := c.Node(id)
+		if !ok {
+			result.Mismatches = append(result.Mismatches, &Mismatch{NodeID: id, Expected: expected.String(), Actual: missing})
+			continue
+		}
+
+		if node.Status != expected {
+			result.Mismatches = append(result.Mismatches, &Mismatch{NodeID: id, Expected: expected.String(), Actual: node.Status.String()})
+		}
+	}
+
+	return result
+}
+
+func (s *Scenario) correlate() (*correlation.Correlation, error) {
+	bundle, err := s.Bundle()
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle.Correlation()
+}
+
+func (r *Result) String() string {
+	var sb strings.Builder
+
+	switch {
+	case r.Error != "":
+		fmt.Fprintf(&sb, "ERROR %s: %s", r.Scenario, r.Error)
+	case r.Passed():
+		fmt.Fprintf(&sb, "PASS  %s (%d checked)", r.Scenario, r.Checked)
+	default:
+		fmt.Fprintf(&sb, "FAIL  %s (%d of %d mismatched)", r.Scenario, len(r.Mismatches), r.Checked)
+		for _, m := range r.Mismatches {
+			fmt.Fprintf(&sb, "\n      %s", m)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario describes an FTTH network as a tree, the device statuses observed
+// on it and the node statuses correlation is expected to produce.
+//
+//	name: drop fiber cut
+//	network:
+//	  - co: CO-1
+//	    children:
+//	      - dio: DIO-1
+//	        children:
+//	          - fiber: F-1
+//	            children:
+//	              - splitter: S-1
+//	                ratio: 1:8
+//	                children:
+//	                  - cto: CTO-1
+//	                    children:
+//	                      - fiber: F-2
+//	                        children:
+//	                          - onu: ABCD00000001
+//	observed:
+//	  alarmed: [ABCD00000001]
+//	expect:
+//	  F-2: ALARMED
+//	  S-1: PROBABLY_ALARMED
+//
+// A scenario describing a network that must be rejected sets error to a
+// part of the expected message instead of listing node statuses.
+type Scenario struct {
+	Name     string            `yaml:"name"`
+	Network  []*Node           `yaml:"network"`
+	Observed Observed          `yaml:"observed"`
+	Expect   map[string]string `yaml:"expect"`
+	Error    string            `yaml:"error"`
+}
+
+// Observed lists devices by reported status. Whether an ID is a sensor or an
+// ONU is taken from the network.
+type Observed struct {
+	Active   []string `yaml:"active"`
+	Alarmed  []string `yaml:"alarmed"`
+	Inactive []string `yaml:"inactive"`
+}
+
+// Node is a network element. Exactly one of the kind keys must be set and
+// holds the element ID. Closures (ceo, cto) group the fibers below them and
+// do not sit on the signal path themselves.
+type Node struct {
+	CO       string `yaml:"co"`
+	DIO      string `yaml:"dio"`
+	Fiber    string `yaml:"fiber"`
+	Splitter string `yaml:"splitter"`
+	CEO      string `yaml:"ceo"`
+	CTO      string `yaml:"cto"`
+	Sensor   string `yaml:"sensor"`
+	ONU      string `yaml:"onu"`
+
+	Name     string   `yaml:"name"`
+	Ratio    string   `yaml:"ratio"`
+	Status   string   `yaml:"status"`
+	Parents  []string `yaml:"parents"`
+	Children []*Node  `yaml:"children"`
+}
+
+func (n *Node) kind() (string, string, error) {
+	kinds := []struct{ kind, id string }{
+		{"co", n.CO},
+		{"dio", n.DIO},
+		{"fiber", n.Fiber},
+		{"splitter", n.Splitter},
+		{"ceo", n.CEO},
+		{"cto", n.CTO},
+		{"sensor", n.Sensor},
+		{"onu", n.ONU},
+	}
+
+	var kind, id string
+	for _, k := range kinds {
+		if k.id == "" {
+			continue
+		}
+		if kind != "" {
+			return "", "", fmt.Errorf("node %s is both %s and %s", id, kind, k.kind)
+		}
+		kind, id = k.kind, k.id
+	}
+
+	if kind == "" {
+		return "", "", errors.New("node has no kind (co, dio, fiber, splitter, ceo, cto, sensor or onu)")
+	}
+
+	return kind, id, nil
+}
+
+// outputs parses the splitter ratio, written as "1:8" or "8".
+func (n *Node) outputs() (int, error) {
+	if n.Ratio == "" {
+		return 0, nil
+	}
+
+	_, outputs, ok := strings.Cut(n.Ratio, ":")
+	if !ok {
+		outputs = n.Ratio
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(outputs))
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("splitter %s has invalid ratio %q", n.Splitter, n.Ratio)
+	}
+
+	return count, nil
+}
+
+// Load reads every scenario of a YAML file. Several scenarios can share a
+// file as separate YAML documents.
+func Load(path string) ([]*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scenarios, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, s := range scenarios {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("%s#%d", path, i+1)
+		}
+	}
+
+	return scenarios, nil
+}
+
+func Parse(r io.Reader) ([]*Scenario, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	scenarios := make([]*Scenario, 0)
+	for {
+		var s Scenario
+		err := dec.Decode(&s)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scenarios = append(scenarios, &s)
+	}
+
+	return scenarios, nil
+}
@@ -0,0 +1,81 @@
+package scenario
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "scenarios", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no scenario files found")
+	}
+
+	for _, path := range paths {
+		scenarios, err := Load(path)
+		if err != nil {
+			t.Fatalf("load %s: %v", path, err)
+		}
+
+		for _, s := range scenarios {
+			t.Run(s.Name, func(t *testing.T) {
+				result := s.Run()
+				if result.Checked == 0 {
+					t.Fatal("scenario checks nothing")
+				}
+				if !result.Passed() {
+					t.Error(result)
+				}
+			})
+		}
+	}
+}
+
+func TestRunReportsMismatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		scenario   *Scenario
+		mismatches int
+		wantErr    bool
+	}{
+		{
+			name: "wrong status",
+			scenario: &Scenario{
+				Network: []*Node{{CO: "CO-1", Children: []*Node{
+					{Fiber: "F-1", Children: []*Node{{ONU: "ABCD00000001"}}},
+				}}},
+				Observed: Observed{
+					Alarmed: []string{"ABCD00000001"},
+				},
+				Expect: map[string]string{"F-1": "ACTIVE", "F-9": "ACTIVE"},
+			},
+			mismatches: 2,
+		},
+		{
+			name: "expected error not raised",
+			scenario: &Scenario{
+				Network: []*Node{{CO: "CO-1", Children: []*Node{{Fiber: "F-1"}}}},
+				Error:   "more than its ratio",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.scenario.Run()
+			if result.Passed() {
+				t.Fatal("got passing result, want failure")
+			}
+			if got := len(result.Mismatches); got != tt.mismatches {
+				t.Errorf("got %d mismatches, want %d", got, tt.mismatches)
+			}
+			if (result.Error != "") != tt.wantErr {
+				t.Errorf("got error %q, want error %v", result.Error, tt.wantErr)
+			}
+		})
+	}
+}
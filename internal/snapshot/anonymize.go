@@ -0,0 +1,158 @@
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+)
+
+type anonymizer struct {
+	key   []byte
+	names map[string]string
+}
+
+// Anonymize returns a copy of the bundle where every identifier, name,
+// DevEUI and serial number is replaced by a keyed pseudonym and locations
+// and cable routes are dropped. Pseudonyms are consistent within the
+// bundle, so correlating it yields the same statuses as the original, but
+// the key is discarded and the mapping cannot be reversed.
+func (b *Bundle) Anonymize() (*Bundle, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &anonymizer{key: key, names: make(map[string]string)}
+	t := b.Topology
+
+	anonymized := *b
+	anonymized.Anonymized = true
+	anonymized.TenantID = a.id("tenant", b.TenantID)
+	anonymized.ProjectIDs = a.ids("project", b.ProjectIDs)
+	anonymized.Topology = &data.Topology{}
+
+	for _, connection := range t.Connections {
+		id := a.id("nc", connection.ID)
+		anonymized.Topology.Connections = append(anonymized.Topology.Connections, &data.Connection{
+			ID:          id,
+			Name:        id,
+			ParentIDs:   a.list("nc", connection.ParentIDs),
+			ChildrenIDs: a.list("nc", connection.ChildrenIDs),
+			Type:        connection.Type,
+		})
+	}
+
+	for _, sensor := range t.Sensors {
+		anonymized.Topology.Sensors = append(anonymized.Topology.Sensors, &data.Sensor{
+			ID:      a.id("nc", sensor.ID),
+			DevEUI:  a.devEUI(sensor.DevEUI),
+			Status:  sensor.Status,
+			FiberID: a.id("nc", sensor.FiberID),
+		})
+	}
+
+	for _, onu := range t.ONUs {
+		anonymized.Topology.ONUs = append(anonymized.Topology.ONUs, &data.ONU{
+			ID:           a.id("nc", onu.ID),
+			SerialNumber: a.serialNumber(onu.SerialNumber),
+			Status:       onu.Status,
+			FiberID:      a.id("nc", onu.FiberID),
+		})
+	}
+
+	for _, component := range t.Components {
+		anonymized.Topology.Components = append(anonymized.Topology.Components, &data.Component{
+			ID:       a.id("nc", component.ID),
+			FiberIDs: a.list("nc", component.FiberIDs),
+			Type:     component.Type,
+		})
+	}
+
+	for _, span := range t.Spans {
+		anonymized.Topology.Spans = append(anonymized.Topology.Spans, &data.Span{
+			FiberID:    a.id("nc", span.FiberID),
+			SegmentID:  a.id("nc", span.SegmentID),
+			Length:     span.Length,
+			ClosureIDs: a.list("nc", span.ClosureIDs),
+		})
+	}
+
+	for _, owner := range t.Owners {
+		anonymized.Topology.Owners = append(anonymized.Topology.Owners, &data.Owner{
+			NetworkComponentID: a.id("nc", owner.NetworkComponentID),
+			ProjectID:          a.id("project", owner.ProjectID),
+		})
+	}
+
+	s := b.EquipmentStatus
+	anonymized.EquipmentStatus = EquipmentStatus{
+		ActiveSensors:   a.apply(a.devEUI, s.ActiveSensors),
+		AlarmedSensors:  a.apply(a.devEUI, s.AlarmedSensors),
+		InactiveSensors: a.apply(a.devEUI, s.InactiveSensors),
+		ActiveONUs:      a.apply(a.serialNumber, s.ActiveONUs),
+		AlarmedONUs:     a.apply(a.serialNumber, s.AlarmedONUs),
+	}
+
+	return &anonymized, nil
+}
+
+func (a *anonymizer) hash(kind, value string, size int) string {
+	key := kind + ":" + value
+	if name, ok := a.names[key]; ok {
+		return name
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(key))
+	name := hex.EncodeToString(mac.Sum(nil))[:size]
+	a.names[key] = name
+
+	return name
+}
+
+func (a *anonymizer) id(kind, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return kind + "-" + a.hash(kind, value, 12)
+}
+
+func (a *anonymizer) ids(kind string, values []string) []string {
+	return a.apply(func(value string) string { return a.id(kind, value) }, values)
+}
+
+func (a *anonymizer) list(kind string, value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	joined := strings.Join(a.ids(kind, strings.Split(*value, ",")), ",")
+	return &joined
+}
+
+func (a *anonymizer) devEUI(value string) string {
+	return strings.ToUpper(a.hash("deveui", value, 16))
+}
+
+func (a *anonymizer) serialNumber(value string) string {
+	return "ANON" + strings.ToUpper(a.hash("serial", value, 8))
+}
+
+func (a *anonymizer) apply(f func(string) string, values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, f(value))
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/matheusrb95/fibergraph/internal/correlation"
+	"github.com/matheusrb95/fibergraph/internal/data"
+)
+
+const FormatVersion = 1
+
+var ErrUnsupportedVersion = errors.New("unsupported snapshot version")
+
+type EquipmentStatus struct {
+	ActiveSensors   []string `json:"active_sensors"`
+	AlarmedSensors  []string `json:"alarmed_sensors"`
+	InactiveSensors []string `json:"inactive_sensors"`
+	ActiveONUs      []string `json:"active_onus"`
+	AlarmedONUs     []string `json:"alarmed_onus"`
+}
+
+// Bundle holds everything needed to reproduce a correlation run: the exact
+// topology rows it was built from and the equipment status it received.
+type Bundle struct {
+	Version         int             `json:"version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	TenantID        string          `json:"tenant_id"`
+	ProjectIDs      []string        `json:"project_ids"`
+	Anonymized      bool            `json:"anonymized"`
+	Topology        *data.Topology  `json:"topology"`
+	EquipmentStatus EquipmentStatus `json:"equipment_status"`
+}
+
+// New captures a topology and the equipment status to correlate it with.
+func New(tenantID string, projectIDs []string, topology *data.Topology, status EquipmentStatus) *Bundle {
+	return &Bundle{
+		Version:         FormatVersion,
+		CreatedAt:       time.Now().UTC(),
+		TenantID:        tenantID,
+		ProjectIDs:      projectIDs,
+		Topology:        topology,
+		EquipmentStatus: status,
+	}
+}
+
+func Write(w io.Writer, b *Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(b)
+}
+
+func Read(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	err := json.NewDecoder(r).Decode(&b)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.Check()
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+func (b *Bundle) Check() error {
+	if b.Version != FormatVersion {
+		return fmt.Errorf("%w %d, want %d", ErrUnsupportedVersion, b.Version, FormatVersion)
+	}
+
+	if b.Topology == nil {
+		return errors.New("snapshot has no topology")
+	}
+
+	return nil
+}
+
+// Correlation rebuilds the correlation captured in the bundle and runs it.
+func (b *Bundle) Correlation() (*correlation.Correlation, error) {
+	c := correlation.New(
+		b.Topology.Connections,
+		b.Topology.Sensors,
+		b.Topology.ONUs,
+		b.EquipmentStatus.ActiveSensors,
+		b.EquipmentStatus.AlarmedSensors,
+		b.EquipmentStatus.InactiveSensors,
+		b.EquipmentStatus.ActiveONUs,
+		b.EquipmentStatus.AlarmedONUs,
+		b.Topology.Components,
+		b.Topology.Spans,
+		b.Topology.Locations,
+		b.Topology.Routes,
+		b.Topology.Owners,
+	)
+	if err := c.Run(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
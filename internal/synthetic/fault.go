@@ -0,0 +1,124 @@
+package synthetic
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+// Fault is the ground truth of a set of fiber cuts: the cut fibers, every
+// connection left without signal and the status each device would report.
+type Fault struct {
+	Cuts   []string                 `json:"cuts"`
+	Dark   []string                 `json:"dark"`
+	Status snapshot.EquipmentStatus `json:"equipment_status"`
+}
+
+// RandomCuts picks count fibers of the network, none of them upstream of
+// another, so that no cut is masked by the darkness of another one.
+func (n *Network) RandomCuts(count int) ([]string, error) {
+	if count < 0 || count > len(n.Fibers) {
+		return nil, fmt.Errorf("cannot cut %d of %d fibers", count, len(n.Fibers))
+	}
+
+	blocked := make(map[string]bool)
+	cuts := make([]string, 0, count)
+	for _, i := range n.rng.Perm(len(n.Fibers)) {
+		if len(cuts) == count {
+			break
+		}
+
+		fiber := n.Fibers[i]
+		if blocked[fiber] {
+			continue
+		}
+
+		cuts = append(cuts, fiber)
+		n.mark(fiber, n.parents, blocked)
+		n.mark(fiber, n.children, blocked)
+	}
+
+	if len(cuts) < count {
+		return nil, fmt.Errorf("cannot cut %d independent fibers, found %d", count, len(cuts))
+	}
+
+	return cuts, nil
+}
+
+// mark adds id and every connection reachable from it along edges to marked.
+func (n *Network) mark(id string, edges map[string][]string, marked map[string]bool) {
+	stack := []string{id}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, next := range edges[current] {
+			if !marked[next] {
+				marked[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	marked[id] = true
+}
+
+// Fault derives the ground truth for the given cuts. A connection keeps its
+// signal while any of its parents does, so protected paths survive a
+// single cut.
+func (n *Network) Fault(cuts ...string) (*Fault, error) {
+	cut := make(map[string]bool, len(cuts))
+	for _, id := range cuts {
+		if n.types[id] != "Fiber" {
+			return nil, fmt.Errorf("%s is not a fiber of the network", id)
+		}
+		cut[id] = true
+	}
+
+	lit := make(map[string]bool)
+	queue := make([]string, 0)
+	for _, connection := range n.Topology.Connections {
+		if connection.Type == "CO" {
+			lit[connection.ID] = true
+			queue = append(queue, connection.ID)
+		}
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, child := range n.children[id] {
+			if lit[child] || cut[child] {
+				continue
+			}
+			lit[child] = true
+			queue = append(queue, child)
+		}
+	}
+
+	fault := &Fault{Cuts: slices.Clone(cuts), Dark: make([]string, 0)}
+	for _, connection := range n.Topology.Connections {
+		if !lit[connection.ID] {
+			fault.Dark = append(fault.Dark, connection.ID)
+		}
+	}
+
+	status := &fault.Status
+	for _, sensor := range n.Topology.Sensors {
+		if lit[sensor.FiberID] {
+			status.ActiveSensors = append(status.ActiveSensors, sensor.DevEUI)
+		} else {
+			status.AlarmedSensors = append(status.AlarmedSensors, sensor.DevEUI)
+		}
+	}
+	for _, onu := range n.Topology.ONUs {
+		if lit[onu.FiberID] {
+			status.ActiveONUs = append(status.ActiveONUs, onu.SerialNumber)
+		} else {
+			status.AlarmedONUs = append(status.AlarmedONUs, onu.SerialNumber)
+		}
+	}
+
+	return fault, nil
+}
@@ -0,0 +1,271 @@
+package synthetic
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/matheusrb95/fibergraph/internal/data"
+	"github.com/matheusrb95/fibergraph/internal/snapshot"
+)
+
+// Config shapes a generated network. Every CO feeds DIOs, every DIO feeds
+// feeder fibers and every feeder ends in a cascade of Depth splitter levels.
+// The last level sits in CTOs and feeds the drop fibers of the ONUs; the
+// levels above sit in CEOs.
+type Config struct {
+	Seed          uint64
+	COs           int
+	DIOsPerCO     int
+	FeedersPerDIO int
+	Depth         int
+	Ratios        []int
+	Fill          float64
+	ONUsPerDrop   int
+	SensorDensity float64
+	Protection    float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Seed:          1,
+		COs:           1,
+		DIOsPerCO:     2,
+		FeedersPerDIO: 4,
+		Depth:         2,
+		Ratios:        []int{8, 16},
+		Fill:          1,
+		ONUsPerDrop:   1,
+		SensorDensity: 0.05,
+		Protection:    0,
+	}
+}
+
+func (cfg Config) Check() error {
+	switch {
+	case cfg.COs <= 0 || cfg.DIOsPerCO <= 0 || cfg.FeedersPerDIO <= 0:
+		return errors.New("cos, dios per co and feeders per dio must be positive")
+	case cfg.Depth <= 0:
+		return errors.New("depth must be positive")
+	case len(cfg.Ratios) == 0:
+		return errors.New("at least one splitter ratio is required")
+	case cfg.Fill <= 0 || cfg.Fill > 1:
+		return errors.New("fill must be in (0, 1]")
+	case cfg.ONUsPerDrop < 0:
+		return errors.New("onus per drop cannot be negative")
+	case cfg.SensorDensity < 0 || cfg.SensorDensity > 1:
+		return errors.New("sensor density must be in [0, 1]")
+	case cfg.Protection < 0 || cfg.Protection > 1:
+		return errors.New("protection must be in [0, 1]")
+	case cfg.Protection > 0 && cfg.DIOsPerCO < 2:
+		return errors.New("protection requires at least two dios per co")
+	}
+
+	for _, ratio := range cfg.Ratios {
+		if ratio <= 0 {
+			return fmt.Errorf("invalid splitter ratio 1:%d", ratio)
+		}
+	}
+
+	return nil
+}
+
+// Network is a generated topology together with the graph needed to derive
+// ground truth for faults injected into it.
+type Network struct {
+	Topology *data.Topology
+	Fibers   []string
+
+	rng      *rand.Rand
+	counters map[string]int
+	types    map[string]string
+	parents  map[string][]string
+	children map[string][]string
+	members  map[string][]string
+}
+
+func Generate(cfg Config) (*Network, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+
+	n := &Network{
+		Topology: &data.Topology{},
+		rng:      rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		counters: make(map[string]int),
+		types:    make(map[string]string),
+		parents:  make(map[string][]string),
+		children: make(map[string][]string),
+		members:  make(map[string][]string),
+	}
+
+	for range cfg.COs {
+		co := n.connection("CO", "CO")
+
+		dios := make([]string, 0, cfg.DIOsPerCO)
+		for range cfg.DIOsPerCO {
+			dios = append(dios, n.connection("DIO", "DIO", co))
+		}
+
+		for i, dio := range dios {
+			for range cfg.FeedersPerDIO {
+				feeder := n.fiber(cfg, "", dio)
+				splitter := n.connection("Splitter", "S", feeder)
+
+				if n.rng.Float64() < cfg.Protection {
+					backup := dios[(i+1)%len(dios)]
+					protection := n.fiber(cfg, "", backup)
+					n.link(splitter, protection)
+				}
+
+				n.cascade(cfg, splitter, 0)
+			}
+		}
+	}
+
+	n.finish()
+
+	return n, nil
+}
+
+// FromTopology wraps existing topology rows so faults can be injected into
+// a real network as well.
+func FromTopology(topology *data.Topology, seed uint64) *Network {
+	n := &Network{
+		Topology: topology,
+		rng:      rand.New(rand.NewPCG(seed, seed)),
+		counters: make(map[string]int),
+		types:    make(map[string]string),
+		parents:  make(map[string][]string),
+		children: make(map[string][]string),
+		members:  make(map[string][]string),
+	}
+
+	for _, connection := range topology.Connections {
+		if _, ok := n.types[connection.ID]; !ok {
+			n.types[connection.ID] = connection.Type
+			if connection.Type == "Fiber" {
+				n.Fibers = append(n.Fibers, connection.ID)
+			}
+		}
+
+		if connection.ParentIDs == nil || *connection.ParentIDs == "" {
+			continue
+		}
+		for _, parentID := range strings.Split(*connection.ParentIDs, ",") {
+			if !slices.Contains(n.parents[connection.ID], parentID) {
+				n.link(connection.ID, parentID)
+			}
+		}
+	}
+
+	return n
+}
+
+// cascade fills the outputs of a splitter at the given level.
+func (n *Network) cascade(cfg Config, splitter string, level int) {
+	ratio := cfg.Ratios[min(level, len(cfg.Ratios)-1)]
+	outputs := max(1, int(math.Round(float64(ratio)*cfg.Fill)))
+	last := level == cfg.Depth-1
+
+	kind := "CEO"
+	if last {
+		kind = "CTO"
+	}
+	closure := n.id(kind)
+	n.Topology.Components = append(n.Topology.Components, &data.Component{ID: closure, Type: kind})
+
+	for range outputs {
+		fiber := n.fiber(cfg, closure, splitter)
+
+		if !last {
+			n.cascade(cfg, n.connection("Splitter", "S", fiber), level+1)
+			continue
+		}
+
+		for range cfg.ONUsPerDrop {
+			serial := fmt.Sprintf("SYNT%08X", n.next("ONU"))
+			n.Topology.ONUs = append(n.Topology.ONUs, &data.ONU{ID: serial, SerialNumber: serial, FiberID: fiber})
+		}
+	}
+}
+
+func (n *Network) fiber(cfg Config, closure string, parent string) string {
+	fiber := n.connection("Fiber", "F", parent)
+	n.Fibers = append(n.Fibers, fiber)
+
+	if closure != "" {
+		n.members[closure] = append(n.members[closure], fiber)
+	}
+
+	if n.rng.Float64() < cfg.SensorDensity {
+		devEUI := fmt.Sprintf("%016X", 0xA000000000000000|uint64(n.next("SENSOR")))
+		n.Topology.Sensors = append(n.Topology.Sensors, &data.Sensor{ID: devEUI, DevEUI: devEUI, FiberID: fiber})
+	}
+
+	return fiber
+}
+
+func (n *Network) connection(connectionType, prefix string, parents ...string) string {
+	id := n.id(prefix)
+	n.types[id] = connectionType
+	n.Topology.Connections = append(n.Topology.Connections, &data.Connection{ID: id, Name: id, Type: connectionType})
+	n.link(id, parents...)
+
+	return id
+}
+
+func (n *Network) link(id string, parents ...string) {
+	for _, parent := range parents {
+		n.parents[id] = append(n.parents[id], parent)
+		n.children[parent] = append(n.children[parent], id)
+	}
+}
+
+func (n *Network) id(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, n.next(prefix))
+}
+
+func (n *Network) next(prefix string) int {
+	n.counters[prefix]++
+	return n.counters[prefix]
+}
+
+func (n *Network) finish() {
+	for _, connection := range n.Topology.Connections {
+		connection.ParentIDs = data.JoinIDs(n.parents[connection.ID])
+		connection.ChildrenIDs = data.JoinIDs(n.children[connection.ID])
+	}
+
+	for _, component := range n.Topology.Components {
+		component.FiberIDs = data.JoinIDs(n.members[component.ID])
+	}
+}
+
+// Dataset wraps the network as a single project of a topology dataset that
+// can be served with data.NewFileSource.
+func (n *Network) Dataset(tenantID, projectID string) *data.Dataset {
+	return &data.Dataset{
+		Tenants: map[string]map[string]*data.Topology{
+			tenantID: {projectID: n.Topology},
+		},
+	}
+}
+
+// Bundle packs the network and the device statuses of a fault as a snapshot.
+func (n *Network) Bundle(tenantID, projectID string, fault *Fault) *snapshot.Bundle {
+	bundle := &snapshot.Bundle{
+		Version:    snapshot.FormatVersion,
+		TenantID:   tenantID,
+		ProjectIDs: []string{projectID},
+		Topology:   n.Topology,
+	}
+	if fault != nil {
+		bundle.EquipmentStatus = fault.Status
+	}
+
+	return bundle
+}
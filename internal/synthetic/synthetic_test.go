@@ -0,0 +1,119 @@
+package synthetic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "default",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "protection across two dios",
+			modify: func(cfg *Config) { cfg.Protection = 1 },
+		},
+		{
+			name: "protection with a single dio",
+			modify: func(cfg *Config) {
+				cfg.DIOsPerCO = 1
+				cfg.Protection = 0.5
+			},
+			wantErr: "at least two dios",
+		},
+		{
+			name:    "no ratios",
+			modify:  func(cfg *Config) { cfg.Ratios = nil },
+			wantErr: "splitter ratio",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Check()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateProtection(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.FeedersPerDIO = 2
+	cfg.Depth = 1
+	cfg.Protection = 1
+
+	n, err := Generate(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dio := func(fiber string) string {
+		return n.parents[fiber][0]
+	}
+
+	splitters := 0
+	for _, connection := range n.Topology.Connections {
+		if connection.Type != "Splitter" {
+			continue
+		}
+		splitters++
+
+		feeders := n.parents[connection.ID]
+		if len(feeders) != 2 {
+			t.Fatalf("splitter %s has %d feeders, want 2", connection.ID, len(feeders))
+		}
+		if dio(feeders[0]) == dio(feeders[1]) {
+			t.Errorf("splitter %s is protected from its own dio %s", connection.ID, dio(feeders[0]))
+		}
+	}
+
+	if splitters != cfg.DIOsPerCO*cfg.FeedersPerDIO {
+		t.Errorf("got %d splitters, want %d", splitters, cfg.DIOsPerCO*cfg.FeedersPerDIO)
+	}
+}
+
+func TestRandomCutsAreIndependent(t *testing.T) {
+	n, err := Generate(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 100 {
+		cuts, err := n.RandomCuts(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, cut := range cuts {
+			below := make(map[string]bool)
+			n.mark(cut, n.children, below)
+			for _, other := range cuts {
+				if other != cut && below[other] {
+					t.Fatalf("cut %s is downstream of cut %s", other, cut)
+				}
+			}
+		}
+	}
+
+	_, err = n.RandomCuts(len(n.Fibers))
+	if err == nil || !strings.Contains(err.Error(), "independent") {
+		t.Errorf("got error %v, want one about independent fibers", err)
+	}
+}
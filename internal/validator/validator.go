@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"regexp"
+	"slices"
+)
+
+var (
+	DevEUIRX    = regexp.MustCompile("^[0-9A-Fa-f]{16}$")
+	ONUSerialRX = regexp.MustCompile("^[A-Za-z0-9]{4}[0-9A-Fa-f]{8}$")
+)
+
+type Validator struct {
+	Errors map[string]string
+}
+
+func New() *Validator {
+	return &Validator{Errors: make(map[string]string)}
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+func (v *Validator) AddError(key, message string) {
+	if _, exists := v.Errors[key]; !exists {
+		v.Errors[key] = message
+	}
+}
+
+func (v *Validator) Check(ok bool, key, message string) {
+	if !ok {
+		v.AddError(key, message)
+	}
+}
+
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}
+
+func In[T comparable](value T, permittedValues ...T) bool {
+	return slices.Contains(permittedValues, value)
+}
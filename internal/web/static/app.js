@@ -0,0 +1,451 @@
+"use strict";
+
+const NODE_WIDTH = 170;
+const NODE_HEIGHT = 32;
+const GAP_X = 16;
+const GAP_Y = 56;
+const SVG_NS = "http://www.w3.org/2000/svg";
+
+const STATUS_COLORS = {
+	ACTIVE: "green",
+	ALARMED: "red",
+	PROBABLY_ALARMED: "orange",
+	INCONSISTENT: "pink",
+	UNDEFINED: "black",
+};
+
+const state = {
+	nodes: new Map(),
+	edges: [],
+	children: new Map(),
+	parents: new Map(),
+	collapsed: new Set(),
+	selected: null,
+	matches: new Set(),
+	view: { x: 0, y: 0, scale: 1 },
+};
+
+const svg = document.getElementById("graph");
+const message = document.getElementById("message");
+
+function init() {
+	const legend = document.getElementById("legend");
+	for (const [status, color] of Object.entries(STATUS_COLORS)) {
+		const item = document.createElement("li");
+		const swatch = document.createElement("span");
+		swatch.style.borderColor = color;
+		item.append(swatch, status);
+		legend.append(item);
+	}
+
+	const params = new URLSearchParams(location.hash.slice(1));
+	document.getElementById("tenant").value = params.get("tenant") || "";
+	document.getElementById("project").value = params.get("project") || "";
+
+	document.getElementById("load").addEventListener("submit", (event) => {
+		event.preventDefault();
+		load();
+	});
+	document.getElementById("search").addEventListener("submit", (event) => {
+		event.preventDefault();
+		search(document.getElementById("query").value.trim().toLowerCase());
+	});
+	document.getElementById("close").addEventListener("click", () => select(null));
+
+	enablePanZoom();
+
+	if (params.get("tenant") && params.get("project")) {
+		load();
+	}
+}
+
+async function load() {
+	const tenant = document.getElementById("tenant").value.trim();
+	const project = document.getElementById("project").value.trim();
+	location.hash = new URLSearchParams({ tenant, project }).toString();
+	message.textContent = "loading...";
+
+	try {
+		const url = `../topology/${encodeURIComponent(tenant)}/${encodeURIComponent(project)}?format=cytoscape`;
+		const response = await fetch(url);
+		if (!response.ok) {
+			const body = await response.json().catch(() => ({}));
+			throw new Error(body.error || body.detail || response.statusText);
+		}
+
+		const graph = await response.json();
+		setGraph(graph.elements);
+		message.textContent = "";
+	} catch (err) {
+		message.textContent = err.message;
+	}
+}
+
+function setGraph(elements) {
+	state.nodes = new Map(elements.nodes.map((n) => [n.data.id, n.data]));
+	state.edges = elements.edges.map((e) => e.data);
+	state.children = new Map();
+	state.parents = new Map();
+	for (const id of state.nodes.keys()) {
+		state.children.set(id, []);
+		state.parents.set(id, []);
+	}
+	for (const edge of state.edges) {
+		state.children.get(edge.source).push(edge.target);
+		state.parents.get(edge.target).push(edge.source);
+	}
+
+	state.collapsed = new Set();
+	state.matches = new Set();
+	state.selected = null;
+	state.view = { x: 0, y: 0, scale: 1 };
+	render();
+}
+
+function closureKey(id) {
+	return `closure:${id}`;
+}
+
+function visibleGraph() {
+	const nodes = new Map();
+	const alias = new Map();
+
+	for (const node of state.nodes.values()) {
+		if (node.closure_id && state.collapsed.has(node.closure_id)) {
+			const key = closureKey(node.closure_id);
+			if (!nodes.has(key)) {
+				nodes.set(key, {
+					id: key,
+					name: `${node.closure_type} ${node.closure_id}`,
+					status: node.closure_status,
+					closure: node.closure_id,
+					members: 0,
+				});
+			}
+			nodes.get(key).members++;
+			alias.set(node.id, key);
+			continue;
+		}
+
+		nodes.set(node.id, node);
+		alias.set(node.id, node.id);
+	}
+
+	const seen = new Set();
+	const edges = [];
+	for (const edge of state.edges) {
+		const source = alias.get(edge.source);
+		const target = alias.get(edge.target);
+		const key = `${source}->${target}`;
+		if (source === target || seen.has(key)) {
+			continue;
+		}
+		seen.add(key);
+		edges.push({ source, target });
+	}
+
+	return { nodes, edges };
+}
+
+function layout(nodes, edges) {
+	const children = new Map();
+	const parents = new Map();
+	const inDegree = new Map();
+	for (const id of nodes.keys()) {
+		children.set(id, []);
+		parents.set(id, []);
+		inDegree.set(id, 0);
+	}
+	for (const edge of edges) {
+		children.get(edge.source).push(edge.target);
+		parents.get(edge.target).push(edge.source);
+		inDegree.set(edge.target, inDegree.get(edge.target) + 1);
+	}
+
+	const depth = new Map();
+	const queue = [];
+	for (const [id, degree] of inDegree) {
+		if (degree === 0) {
+			queue.push(id);
+			depth.set(id, 0);
+		}
+	}
+	while (queue.length > 0) {
+		const id = queue.shift();
+		for (const child of children.get(id)) {
+			depth.set(child, Math.max(depth.get(child) || 0, depth.get(id) + 1));
+			inDegree.set(child, inDegree.get(child) - 1);
+			if (inDegree.get(child) === 0) {
+				queue.push(child);
+			}
+		}
+	}
+
+	const layers = [];
+	for (const id of nodes.keys()) {
+		const d = depth.get(id) || 0;
+		while (layers.length <= d) {
+			layers.push([]);
+		}
+		layers[d].push(id);
+	}
+
+	const column = new Map();
+	layers.forEach((layer, d) => {
+		if (d > 0) {
+			const barycenter = (id) => {
+				const ps = parents.get(id).filter((p) => column.has(p));
+				if (ps.length === 0) {
+					return 0;
+				}
+				return ps.reduce((sum, p) => sum + column.get(p), 0) / ps.length;
+			};
+			layer.sort((a, b) => barycenter(a) - barycenter(b));
+		}
+		layer.forEach((id, i) => column.set(id, i));
+	});
+
+	const positions = new Map();
+	layers.forEach((layer, d) => {
+		layer.forEach((id, i) => {
+			positions.set(id, { x: i * (NODE_WIDTH + GAP_X), y: d * (NODE_HEIGHT + GAP_Y) });
+		});
+	});
+
+	return positions;
+}
+
+function render() {
+	const { nodes, edges } = visibleGraph();
+	const positions = layout(nodes, edges);
+
+	svg.replaceChildren();
+	const root = element("g", { id: "viewport" });
+	svg.append(root);
+
+	for (const edge of edges) {
+		const from = positions.get(edge.source);
+		const to = positions.get(edge.target);
+		const x1 = from.x + NODE_WIDTH / 2;
+		const y1 = from.y + NODE_HEIGHT;
+		const x2 = to.x + NODE_WIDTH / 2;
+		const y2 = to.y;
+		root.append(element("path", {
+			class: "edge",
+			d: `M${x1} ${y1} C${x1} ${y1 + GAP_Y / 2} ${x2} ${y2 - GAP_Y / 2} ${x2} ${y2}`,
+		}));
+	}
+
+	for (const node of nodes.values()) {
+		const position = positions.get(node.id);
+		const classes = ["node"];
+		if (node.members !== undefined) {
+			classes.push("closure");
+		}
+		if (state.matches.has(node.id)) {
+			classes.push("match");
+		}
+		if (state.selected === node.id) {
+			classes.push("selected");
+		}
+
+		const group = element("g", {
+			class: classes.join(" "),
+			transform: `translate(${position.x} ${position.y})`,
+		});
+		const title = element("title");
+		title.textContent = `${node.name} (${node.status})`;
+		const label = node.members !== undefined ? `${node.name} (${node.members})` : node.name;
+		const text = element("text", { x: NODE_WIDTH / 2, y: NODE_HEIGHT / 2 + 4, "text-anchor": "middle" });
+		text.textContent = label.length > 26 ? `${label.slice(0, 25)}…` : label;
+		group.append(
+			title,
+			element("rect", { width: NODE_WIDTH, height: NODE_HEIGHT, rx: 4, stroke: STATUS_COLORS[node.status] || "black" }),
+			text,
+		);
+
+		group.addEventListener("click", (event) => {
+			event.stopPropagation();
+			if (node.members !== undefined) {
+				state.collapsed.delete(node.closure);
+				render();
+				return;
+			}
+			select(node.id);
+		});
+		group.addEventListener("dblclick", (event) => {
+			event.stopPropagation();
+			if (node.closure_id) {
+				state.collapsed.add(node.closure_id);
+				render();
+			}
+		});
+
+		root.append(group);
+	}
+
+	applyView();
+}
+
+function select(id) {
+	state.selected = id;
+	const details = document.getElementById("details");
+	details.hidden = id === null;
+	render();
+	if (id === null) {
+		return;
+	}
+
+	const node = state.nodes.get(id);
+	const downstream = descendants(id);
+	const devices = downstream.filter((d) => d.type === "SENSOR" || d.type === "ONU");
+	const onus = downstream.filter((d) => d.type === "ONU");
+
+	const body = document.getElementById("details-body");
+	body.replaceChildren();
+	body.append(
+		heading(node.name),
+		table([
+			["id", node.id],
+			["component id", node.component_id],
+			["type", node.type],
+			["status", node.status],
+			["closure", node.closure_id ? `${node.closure_type} ${node.closure_id} (${node.closure_status})` : "-"],
+			["upstream", ancestors(id).map((n) => n.id).join(" ← ") || "-"],
+		]),
+		heading(`Evidence (${devices.length})`),
+		table(devices.map((d) => [d.id, d.status])),
+		heading(`Downstream ONUs (${onus.length})`),
+		table(onus.map((d) => [d.id, d.status])),
+	);
+
+	if (node.closure_id) {
+		const collapse = document.createElement("button");
+		collapse.textContent = `Collapse ${node.closure_type} ${node.closure_id}`;
+		collapse.addEventListener("click", () => {
+			state.collapsed.add(node.closure_id);
+			select(null);
+		});
+		body.append(collapse);
+	}
+}
+
+function descendants(id) {
+	const result = [];
+	const seen = new Set([id]);
+	const stack = [...state.children.get(id)];
+	while (stack.length > 0) {
+		const child = stack.pop();
+		if (seen.has(child)) {
+			continue;
+		}
+		seen.add(child);
+		result.push(state.nodes.get(child));
+		stack.push(...state.children.get(child));
+	}
+	return result;
+}
+
+function ancestors(id) {
+	const result = [];
+	const seen = new Set([id]);
+	let current = id;
+	while (state.parents.get(current).length > 0) {
+		current = state.parents.get(current)[0];
+		if (seen.has(current)) {
+			break;
+		}
+		seen.add(current);
+		result.push(state.nodes.get(current));
+	}
+	return result;
+}
+
+function search(query) {
+	state.matches = new Set();
+	if (query === "") {
+		render();
+		return;
+	}
+
+	for (const node of state.nodes.values()) {
+		if (node.id.toLowerCase().includes(query) || node.name.toLowerCase().includes(query)) {
+			state.matches.add(node.id);
+			if (node.closure_id) {
+				state.collapsed.delete(node.closure_id);
+			}
+		}
+	}
+
+	message.textContent = `${state.matches.size} match(es)`;
+	const [first] = state.matches;
+	if (first !== undefined) {
+		select(first);
+	} else {
+		render();
+	}
+}
+
+function enablePanZoom() {
+	let drag = null;
+
+	svg.addEventListener("mousedown", (event) => {
+		drag = { x: event.clientX - state.view.x, y: event.clientY - state.view.y };
+	});
+	window.addEventListener("mousemove", (event) => {
+		if (drag === null) {
+			return;
+		}
+		state.view.x = event.clientX - drag.x;
+		state.view.y = event.clientY - drag.y;
+		applyView();
+	});
+	window.addEventListener("mouseup", () => {
+		drag = null;
+	});
+	svg.addEventListener("wheel", (event) => {
+		event.preventDefault();
+		const factor = event.deltaY < 0 ? 1.1 : 1 / 1.1;
+		const rect = svg.getBoundingClientRect();
+		const mx = event.clientX - rect.left;
+		const my = event.clientY - rect.top;
+		state.view.x = mx - (mx - state.view.x) * factor;
+		state.view.y = my - (my - state.view.y) * factor;
+		state.view.scale *= factor;
+		applyView();
+	}, { passive: false });
+}
+
+function applyView() {
+	const viewport = document.getElementById("viewport");
+	if (viewport) {
+		viewport.setAttribute("transform", `translate(${state.view.x} ${state.view.y}) scale(${state.view.scale})`);
+	}
+}
+
+function element(name, attributes = {}) {
+	const el = document.createElementNS(SVG_NS, name);
+	for (const [key, value] of Object.entries(attributes)) {
+		el.setAttribute(key, value);
+	}
+	return el;
+}
+
+function heading(text) {
+	const h = document.createElement("h3");
+	h.textContent = text;
+	return h;
+}
+
+function table(rows) {
+	const t = document.createElement("table");
+	for (const cells of rows) {
+		const row = t.insertRow();
+		for (const cell of cells) {
+			row.insertCell().textContent = cell;
+		}
+	}
+	return t;
+}
+
+init();